@@ -0,0 +1,373 @@
+package main
+
+import (
+	"WrapNGo/config"
+	"WrapNGo/logger"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+)
+
+var errUsage = errors.New("invalid usage")
+
+// The command type describes a single subcommand of the cli.
+type command struct {
+	name    string
+	args    string
+	summary string
+
+	// needsConfig defines whether the config has to be loaded before the command runs.
+	needsConfig bool
+
+	// hidden commands are not listed in the help text and completions.
+	hidden bool
+
+	// completeTasks defines whether the positional arguments are task selectors.
+	completeTasks bool
+
+	// completeWords returns the words the positional arguments can be completed with.
+	completeWords func() []string
+
+	// passExtra defines whether the arguments after "--" are passed separately.
+	// If set, they are appended to the positional arguments behind a "--" element (see splitExtra).
+	passExtra bool
+
+	// setup registers the command's flags on fs and returns the function to execute.
+	setup func(fs *flag.FlagSet) func(args []string) error
+
+	// subcommands are selected by the first argument, e.g. "config migrate".
+	// A command with subcommands can not be run itself and does not need a setup.
+	subcommands []*command
+}
+
+// The globalFlags type contains the flags which are valid for every command.
+// The flags overriding GeneralSettings are passed to the config directly (see settingFlag).
+type globalFlags struct {
+	configDir  string
+	configFile string
+	profile    string
+}
+
+var (
+	commands []*command
+	global   globalFlags
+)
+
+// registerCommand adds c to the list of available commands.
+func registerCommand(c *command) {
+	commands = append(commands, c)
+}
+
+// registerSubcommand adds c to the subcommands of the already registered parent command.
+// The name of c is prefixed with the name of its parent.
+func registerSubcommand(parent string, c *command) {
+	p := findCommand(parent)
+	c.name = p.name + " " + c.name
+	p.subcommands = append(p.subcommands, c)
+}
+
+// findSubcommand returns the subcommand with the given name (without the prefix of c) or nil if there is none.
+func (c *command) findSubcommand(name string) *command {
+	for _, sub := range c.subcommands {
+		if sub.name == c.name+" "+name {
+			return sub
+		}
+	}
+	return nil
+}
+
+// subcommandNames returns the names of all subcommands of c without the prefix of c.
+func (c *command) subcommandNames() (names []string) {
+	for _, sub := range c.subcommands {
+		names = append(names, strings.TrimPrefix(sub.name, c.name+" "))
+	}
+	return
+}
+
+// findCommand returns the command with the given name or nil if there is none.
+func findCommand(name string) *command {
+	for _, c := range commands {
+		if c.name == name {
+			return c
+		}
+	}
+	return nil
+}
+
+// visibleCommands returns all commands which are not hidden, sorted by name.
+func visibleCommands() (visible []*command) {
+	for _, c := range commands {
+		if !c.hidden {
+			visible = append(visible, c)
+		}
+	}
+	sort.Slice(visible, func(i, j int) bool {
+		return visible[i].name < visible[j].name
+	})
+	return
+}
+
+// commandNames returns the names of all visible commands.
+func commandNames() (names []string) {
+	for _, c := range visibleCommands() {
+		names = append(names, c.name)
+	}
+	return
+}
+
+// progName returns the name of the executable.
+func progName() string {
+	return filepath.Base(os.Args[0])
+}
+
+// newGlobalFlagSet creates the flag set containing all global flags.
+func newGlobalFlagSet(out io.Writer) *flag.FlagSet {
+	fs := flag.NewFlagSet(progName(), flag.ContinueOnError)
+	fs.SetOutput(out)
+	fs.StringVar(&global.configDir, "config-dir", "", "directory containing the config files (env: "+config.EnvConfigDir+")")
+	fs.StringVar(&global.configFile, "config-file", "", "main config file to use (env: "+config.EnvConfigFile+")")
+	fs.StringVar(&global.profile, "profile", "", "profile overlaid onto the config (env: "+config.EnvProfile+")")
+	for _, name := range config.SettingNames() {
+		fs.Var(&settingFlag{name: name}, config.SettingFlag(name), "override GeneralSettings."+name+" (env: "+config.SettingEnv(name)+")")
+	}
+	fs.Usage = func() {
+		printUsage(out)
+	}
+	return fs
+}
+
+// newCommandFlagSet creates the flag set of the given command.
+func newCommandFlagSet(c *command, out io.Writer) (fs *flag.FlagSet, run func(args []string) error) {
+	fs = flag.NewFlagSet(c.name, flag.ContinueOnError)
+	fs.SetOutput(out)
+	if c.setup != nil {
+		run = c.setup(fs)
+	}
+	fs.Usage = func() {
+		printCommandUsage(out, c, fs)
+	}
+	return
+}
+
+// execute parses the given arguments, runs the corresponding command and returns the exit code.
+func execute(args []string) (code int) {
+	// Provide a logger until the config has been loaded.
+	logger.NewInstance(false)
+
+	gfs := newGlobalFlagSet(os.Stderr)
+	err := gfs.Parse(args)
+	if err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOK
+		}
+		return exitUsage
+	}
+	if global.configDir != "" {
+		config.SetDir(global.configDir)
+	}
+	if global.configFile != "" {
+		config.SetFile(global.configFile)
+	}
+	if global.profile != "" {
+		config.SetProfile(global.profile)
+	}
+	reserved := make([]string, 0, len(commands))
+	for _, c := range commands {
+		reserved = append(reserved, c.name)
+	}
+	config.SetReservedNames(reserved...)
+
+	args = gfs.Args()
+	if len(args) < 1 {
+		err = prepare()
+		if err != nil {
+			logger.Error(err)
+			return exitCode(err)
+		}
+		interactive()
+		return exitOK
+	}
+
+	// Keep supporting the plain task name as first argument (wrapngo <Task>).
+	// The name is matched exactly, globs, regular expressions and tags are only supported by the run command.
+	// Tasks sharing the same name were always started at once, keep it that way.
+	// Further arguments were always ignored, they are neither selectors nor flags of the run command.
+	c := findCommand(args[0])
+	if c == nil {
+		if len(args) > 1 {
+			logger.Warnf("Ignoring the arguments %q after the task name, use \"run %s -- <args>\" to pass extra arguments\n", args[1:], args[0])
+		}
+		c = findCommand("run")
+		args = []string{"-parallel", "0", "-keep-going", selectorNamePrefix + args[0]}
+	} else {
+		args = args[1:]
+	}
+	if len(c.subcommands) > 0 {
+		var sub *command
+		if len(args) > 0 {
+			sub = c.findSubcommand(args[0])
+		}
+		if sub == nil {
+			fmt.Fprintf(os.Stderr, "%v: %s requires one of the subcommands %s\n\n", errUsage, c.name, strings.Join(c.subcommandNames(), ", "))
+			printCommandUsage(os.Stderr, c, flag.NewFlagSet(c.name, flag.ContinueOnError))
+			return exitUsage
+		}
+		c, args = sub, args[1:]
+	}
+
+	fs, run := newCommandFlagSet(c, os.Stderr)
+	positional, extra, err := parseInterspersed(fs, args)
+	if err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOK
+		}
+		return exitUsage
+	}
+	if c.passExtra && extra != nil {
+		positional = append(append(positional, "--"), extra...)
+	} else {
+		positional = append(positional, extra...)
+	}
+
+	if c.needsConfig {
+		err = prepare()
+		if err != nil {
+			logger.Error(err)
+			return exitCode(err)
+		}
+	}
+
+	err = run(positional)
+	if err == nil {
+		return exitOK
+	}
+	if errors.Is(err, errUsage) {
+		fmt.Fprintf(os.Stderr, "%v\n\n", err)
+		fs.Usage()
+		return exitUsage
+	}
+	logger.Error(err)
+	return exitCode(err)
+}
+
+// parseInterspersed parses the flags of fs which may be placed between positional arguments.
+// Every argument after a "--" terminator is returned as extra instead.
+func parseInterspersed(fs *flag.FlagSet, args []string) (positional, extra []string, err error) {
+	positional = make([]string, 0)
+	for {
+		err = fs.Parse(args)
+		if err != nil {
+			return
+		}
+
+		rest := fs.Args()
+		consumed := len(args) - len(rest)
+		if consumed > 0 && args[consumed-1] == "--" {
+			extra = rest
+			return
+		}
+		if len(rest) < 1 {
+			return
+		}
+		positional = append(positional, rest[0])
+		args = rest[1:]
+	}
+}
+
+// splitExtra splits the arguments of a command with passExtra into positional and extra arguments.
+func splitExtra(args []string) (positional, extra []string) {
+	for i, a := range args {
+		if a == "--" {
+			return args[:i], args[i+1:]
+		}
+	}
+	return args, nil
+}
+
+// printUsage prints the general help text.
+func printUsage(out io.Writer) {
+	name := progName()
+	fmt.Fprintf(out, "Usage:\n  %s [global flags] <command> [flags] [arguments]\n  %s [global flags] <task>\n\n", name, name)
+	fmt.Fprintln(out, "Calling the executable without any arguments starts the interactive mode.")
+	fmt.Fprintln(out)
+	fmt.Fprintln(out, "Commands:")
+
+	tw := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+	for _, c := range visibleCommands() {
+		fmt.Fprintf(tw, "  %s\t%s\n", c.name, c.summary)
+	}
+	_ = tw.Flush()
+
+	fmt.Fprintln(out)
+	fmt.Fprintln(out, "Global flags:")
+	gfs := newGlobalFlagSet(out)
+	gfs.PrintDefaults()
+	fmt.Fprintf(out, "\nUse \"%s help <command>\" for more information about a command.\n", name)
+}
+
+// printCommandUsage prints the help text of a single command.
+func printCommandUsage(out io.Writer, c *command, fs *flag.FlagSet) {
+	flags := " [flags]"
+	if len(c.subcommands) > 0 {
+		flags = ""
+	}
+	fmt.Fprintf(out, "Usage:\n  %s\n\n", strings.TrimSpace(fmt.Sprintf("%s [global flags] %s%s %s", progName(), c.name, flags, c.args)))
+	fmt.Fprintln(out, c.summary)
+	if len(c.subcommands) > 0 {
+		fmt.Fprintln(out)
+		fmt.Fprintln(out, "Subcommands:")
+		tw := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+		for _, sub := range c.subcommands {
+			fmt.Fprintf(tw, "  %s\t%s\n", sub.name, sub.summary)
+		}
+		_ = tw.Flush()
+	}
+
+	hasFlags := false
+	fs.VisitAll(func(*flag.Flag) {
+		hasFlags = true
+	})
+	if !hasFlags {
+		return
+	}
+	fmt.Fprintln(out)
+	fmt.Fprintln(out, "Flags:")
+	fs.PrintDefaults()
+}
+
+// The stringsFlag type is a flag which can be set multiple times.
+type stringsFlag []string
+
+func (f *stringsFlag) String() string {
+	return strings.Join(*f, ",")
+}
+
+func (f *stringsFlag) Set(v string) error {
+	*f = append(*f, v)
+	return nil
+}
+
+// The settingFlag type is a flag overriding a single field of GeneralSettings.
+type settingFlag struct {
+	name  string
+	value string
+}
+
+func (f *settingFlag) String() string {
+	return f.value
+}
+
+func (f *settingFlag) Set(v string) error {
+	f.value = v
+	return config.OverrideSetting(f.name, v)
+}
+
+func (f *settingFlag) IsBoolFlag() bool {
+	return config.SettingIsBool(f.name)
+}
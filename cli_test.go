@@ -0,0 +1,43 @@
+package main
+
+import "testing"
+
+func TestExecuteTaskName(t *testing.T) {
+	setupConfigDir(t, map[string]string{
+		"config.yaml": `Version: 1
+Tasks:
+  - Name: backup-*
+    Command: "true"
+  - Name: backup-db
+    Command: "false"
+    StopIfUnsuccessful: true
+  - Name: tag:nightly
+    Command: "true"
+  - Name: failing
+    Command: "false"
+    StopIfUnsuccessful: true
+    Tags: [nightly]
+`,
+	})
+
+	tests := []struct {
+		name string
+		args []string
+		want int
+	}{
+		{name: "glob characters are matched exactly", args: []string{"backup-*"}, want: exitOK},
+		{name: "tag prefix is matched exactly", args: []string{"tag:nightly"}, want: exitOK},
+		{name: "run selects by glob", args: []string{"run", "backup-*"}, want: exitJobFailed},
+		{name: "run selects by tag", args: []string{"run", "tag:nightly"}, want: exitJobFailed},
+		{name: "unknown task", args: []string{"backup-"}, want: exitTaskNotFound},
+		{name: "arguments after the name are ignored", args: []string{"backup-*", "extra", "-parallel", "x"}, want: exitOK},
+		{name: "arguments after a failing name are ignored", args: []string{"backup-db", "backup-*"}, want: exitJobFailed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := execute(tt.args); got != tt.want {
+				t.Errorf("execute(%q) = %d, want %d", tt.args, got, tt.want)
+			}
+		})
+	}
+}
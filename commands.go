@@ -0,0 +1,270 @@
+package main
+
+import (
+	"WrapNGo/config"
+	"WrapNGo/logger"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+func init() {
+	registerCommand(&command{
+		name:          "run",
+		args:          "<selector> [selector...] [-- extra arguments]",
+		summary:       "Run the tasks matching the given selectors",
+		needsConfig:   true,
+		completeTasks: true,
+		passExtra:     true,
+		setup:         setupRun,
+	})
+	registerCommand(&command{
+		name:          "list",
+		args:          "[selector...]",
+		summary:       "List all configured tasks or the ones matching the given selectors",
+		needsConfig:   true,
+		completeTasks: true,
+		setup:         setupList,
+	})
+	registerCommand(&command{
+		name:          "show",
+		args:          "<selector>",
+		summary:       "Show the configuration of the matching tasks",
+		needsConfig:   true,
+		completeTasks: true,
+		setup:         setupShow,
+	})
+	registerCommand(&command{
+		name:        "show-config",
+		summary:     "Show the effective general settings and where each value comes from",
+		needsConfig: true,
+		setup:       setupShowConfig,
+	})
+	registerCommand(&command{
+		name:    "validate",
+		summary: "Check every config file and report problems with file and line",
+		setup:   setupValidate,
+	})
+	registerCommand(&command{
+		name:    "init",
+		summary: "Create the main config",
+		setup:   setupInit,
+	})
+	registerCommand(&command{
+		name:          "help",
+		args:          "[command]",
+		summary:       "Show the help of the given command",
+		completeWords: commandNames,
+		setup:         setupHelp,
+	})
+}
+
+// setupRun registers the flags of the run command.
+func setupRun(fs *flag.FlagSet) func(args []string) error {
+	opts := runOptions{}
+	tags := stringsFlag{}
+	sets := stringsFlag{}
+	summary := false
+	noDeps := false
+	fs.IntVar(&opts.parallel, "parallel", 1, "maximum number of tasks running at the same time (0 = unlimited)")
+	fs.BoolVar(&opts.keepGoing, "keep-going", false, "continue with the remaining tasks if a task fails")
+	fs.BoolVar(&opts.dryRun, "dry-run", false, "print the resolved commands without executing them")
+	fs.BoolVar(&summary, "summary", false, "print a json summary of all tasks to stderr")
+	fs.BoolVar(&noDeps, "no-deps", false, "do not run the dependencies (DependsOn) of the selected tasks which have not been selected")
+	fs.Var(&tags, "tag", "run all tasks with the given `tag` (can be repeated)")
+	fs.Var(&sets, "set", "override a Dynamic.<name> or GlobalDynamic.<name> value with `Key=Value` (can be repeated)")
+	return func(args []string) (err error) {
+		args, extra := splitExtra(args)
+		overrides, err := parseOverrides(sets, extra)
+		if err != nil {
+			return
+		}
+
+		selectors := append(args, tagSelectors(tags)...)
+		if len(selectors) < 1 {
+			return fmt.Errorf("%w: at least one task selector is required", errUsage)
+		}
+		if opts.parallel < 0 {
+			return fmt.Errorf("%w: -parallel must not be negative", errUsage)
+		}
+
+		results := make([]taskResult, 0)
+		if summary {
+			defer func() {
+				sErr := writeSummary(logger.MaskWriter(os.Stderr), results, exitCode(err))
+				if sErr != nil {
+					logger.Error(sErr)
+				}
+			}()
+		}
+
+		conf := config.CurrentSnapshot()
+		tasks, err := findTasks(conf, selectors)
+		if err != nil {
+			return
+		}
+		for i := range tasks {
+			tasks[i] = overrides.applyTask(tasks[i])
+		}
+		if !noDeps {
+			tasks = withDependencies(conf, tasks)
+		}
+		results, err = runTasks(tasks, overrides.applyGlobal(conf), opts)
+		return
+	}
+}
+
+// setupList registers the flags of the list command.
+func setupList(fs *flag.FlagSet) func(args []string) error {
+	tags := stringsFlag{}
+	fs.Var(&tags, "tag", "only list tasks with the given `tag` (can be repeated)")
+	format := fs.String("format", formatTable, "output format (table, json or yaml)")
+	return func(args []string) (err error) {
+		conf := config.CurrentSnapshot()
+		tasks := conf.Tasks()
+		selectors := append(args, tagSelectors(tags)...)
+		if len(selectors) > 0 {
+			tasks, err = findTasks(conf, selectors)
+			if err != nil {
+				return
+			}
+		}
+		return writeTaskListing(os.Stdout, conf, tasks, *format)
+	}
+}
+
+// setupShow registers the flags of the show command.
+func setupShow(_ *flag.FlagSet) func(args []string) error {
+	return func(args []string) (err error) {
+		if len(args) != 1 {
+			return fmt.Errorf("%w: exactly one task selector is required", errUsage)
+		}
+
+		tasks, err := findTasks(config.CurrentSnapshot(), args)
+		if err != nil {
+			return
+		}
+
+		enc := yaml.NewEncoder(os.Stdout)
+		enc.SetIndent(2)
+		for _, t := range tasks {
+			err = enc.Encode(t)
+			if err != nil {
+				return
+			}
+		}
+		return enc.Close()
+	}
+}
+
+// setupShowConfig registers the flags of the show-config command.
+func setupShowConfig(fs *flag.FlagSet) func(args []string) error {
+	format := fs.String("format", formatTable, "output format (table, json or yaml)")
+	return func(args []string) (err error) {
+		if len(args) > 0 {
+			return fmt.Errorf("%w: show-config does not take any arguments", errUsage)
+		}
+		return writeSettingListing(os.Stdout, config.Settings(), *format)
+	}
+}
+
+// setupValidate registers the flags of the validate command.
+// The config is not loaded beforehand, files which fail to load need to be reported as well.
+func setupValidate(_ *flag.FlagSet) func(args []string) error {
+	return func(args []string) (err error) {
+		if len(args) > 0 {
+			return fmt.Errorf("%w: validate does not take any arguments", errUsage)
+		}
+
+		paths, diags, err := config.Validate()
+		if errors.Is(err, config.ErrNotFound) {
+			return fmt.Errorf("%w: %v, create one with \"%s init\"", ErrInitializing, err, progName())
+		}
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrInitializing, err)
+		}
+
+		numErr := 0
+		for _, d := range diags {
+			fmt.Println(d)
+			if d.Severity == config.SeverityError {
+				numErr++
+			}
+		}
+		if numErr > 0 {
+			return fmt.Errorf("%w: %d error(s) and %d warning(s) found", ErrInvalidConfig, numErr, len(diags)-numErr)
+		}
+		logger.Infof("Configuration is valid, %d file(s) checked, %d warning(s) found\n", len(paths), len(diags))
+		return
+	}
+}
+
+// setupInit registers the flags of the init command.
+func setupInit(fs *flag.FlagSet) func(args []string) error {
+	format := fs.String("format", config.FormatJson, "format of the config ("+strings.Join(config.FormatNames(), ", ")+")")
+	isYaml := fs.Bool("yaml", false, "create the config in the yaml format, shorthand for -format yaml")
+	force := fs.Bool("force", false, "overwrite an already existing main config")
+	template := fs.String("template", config.TemplateDefault, "template to create the config from ("+strings.Join(config.TemplateNames(), ", ")+")")
+	return func(args []string) (err error) {
+		if len(args) > 0 {
+			return fmt.Errorf("%w: init does not take any arguments", errUsage)
+		}
+
+		if *isYaml {
+			*format = config.FormatYaml
+		}
+		path, created, err := config.NewConfigFromTemplate(*template, *force, *format)
+		if errors.Is(err, config.ErrUnknownTemplate) || errors.Is(err, config.ErrUnknownFormat) {
+			return fmt.Errorf("%w: %v", errUsage, err)
+		}
+		if err != nil {
+			return
+		}
+		if !created {
+			return fmt.Errorf("%w: %s (use -force to overwrite)", ErrConfigExists, path)
+		}
+		logger.Infof("Config created, please modify it to your needs: %s\n", path)
+		return
+	}
+}
+
+// setupHelp registers the flags of the help command.
+func setupHelp(_ *flag.FlagSet) func(args []string) error {
+	return func(args []string) (err error) {
+		if len(args) < 1 {
+			printUsage(os.Stdout)
+			return
+		}
+
+		c := findCommand(args[0])
+		if c == nil {
+			return fmt.Errorf("%w: unknown command %s", errUsage, args[0])
+		}
+		if len(args) > 1 && len(c.subcommands) > 0 {
+			c = c.findSubcommand(args[1])
+			if c == nil {
+				return fmt.Errorf("%w: unknown command %s", errUsage, strings.Join(args[:2], " "))
+			}
+		}
+		fs, _ := newCommandFlagSet(c, os.Stdout)
+		fs.Usage()
+		return
+	}
+}
+
+// findTasks returns all tasks of conf matching the given selectors.
+// If any selector does not match a task, ErrTaskNotFound is returned.
+func findTasks(conf *config.Snapshot, selectors []string) (tasks []config.Task, err error) {
+	tasks, unmatched, err := selectTasks(conf.Tasks(), selectors, conf.GeneralSettings().CaseSensitiveJobNames)
+	if err != nil {
+		return
+	}
+	if len(unmatched) > 0 {
+		return nil, fmt.Errorf("%w: %s", ErrTaskNotFound, strings.Join(unmatched, ", "))
+	}
+	return
+}
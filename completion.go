@@ -0,0 +1,449 @@
+package main
+
+import (
+	"WrapNGo/config"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+	"text/template"
+)
+
+const (
+	completionBash = "bash"
+	completionZsh  = "zsh"
+	completionFish = "fish"
+)
+
+var nonIdentReg = regexp.MustCompile("[^a-zA-Z0-9_]")
+
+// completionFlagValues contains the words the values of the flags can be completed with.
+// Keys are either "<command>.<flag>" or only the flag name if the values are the same for every command.
+var completionFlagValues = map[string][]string{
+	"list.format":           {formatTable, formatJson, formatYaml},
+	"show-config.format":    {formatTable, formatJson, formatYaml},
+	"secret list.format":    {formatTable, formatJson, formatYaml},
+	"init.format":           config.FormatNames(),
+	"config convert.to":     config.FormatNames(),
+	"config export.format":  config.FormatNames(),
+	"template":              config.TemplateNames(),
+	"duplicate-task-policy": config.DuplicateTaskPolicies(),
+}
+
+func init() {
+	registerCommand(&command{
+		name:    "completion",
+		args:    "<bash|zsh|fish>",
+		summary: "Generate the shell completion script",
+		completeWords: func() []string {
+			return []string{completionBash, completionZsh, completionFish}
+		},
+		setup: setupCompletion,
+	})
+	registerCommand(&command{
+		name:    "__complete",
+		args:    "tasks [prefix]",
+		summary: "Print the completion candidates used by the completion scripts",
+		hidden:  true,
+		setup:   setupComplete,
+	})
+}
+
+// The completionFlag type describes a single flag for the completion scripts.
+type completionFlag struct {
+	Name   string
+	Usage  string
+	IsBool bool
+	IsDir  bool
+	IsFile bool
+	Words  []string
+}
+
+// The completionCommand type describes a single command for the completion scripts.
+type completionCommand struct {
+	Name    string
+	Summary string
+	Flags   []completionFlag
+	Tasks   bool
+	Words   []string
+}
+
+// The completionData type contains everything needed to render a completion script.
+type completionData struct {
+	Prog     string
+	Func     string
+	Global   []completionFlag
+	Commands []completionCommand
+}
+
+// setupCompletion registers the flags of the completion command.
+func setupCompletion(fs *flag.FlagSet) func(args []string) error {
+	name := fs.String("name", progName(), "name of the executable to complete")
+	return func(args []string) (err error) {
+		if len(args) != 1 {
+			return fmt.Errorf("%w: exactly one shell is required", errUsage)
+		}
+		return writeCompletion(os.Stdout, args[0], *name)
+	}
+}
+
+// setupComplete registers the flags of the hidden __complete command.
+func setupComplete(_ *flag.FlagSet) func(args []string) error {
+	return func(args []string) (err error) {
+		if len(args) < 1 || len(args) > 2 || args[0] != "tasks" {
+			return fmt.Errorf("%w: expected tasks [prefix]", errUsage)
+		}
+		prefix := ""
+		if len(args) > 1 {
+			prefix = args[1]
+		}
+
+		// Completions must never fail, ignore any config error.
+		// The profile selected via -profile or WRAPNGO_PROFILE is applied by Reload.
+		conf, lErr := config.Reload()
+		if lErr != nil {
+			conf = config.CurrentSnapshot()
+		}
+		for _, name := range completeTaskNames(conf, prefix) {
+			fmt.Println(name)
+		}
+		return
+	}
+}
+
+// completeTaskNames returns the unique names of all tasks of conf starting with prefix.
+// The comparison honors GeneralSettings.CaseSensitiveJobNames.
+func completeTaskNames(conf *config.Snapshot, prefix string) (names []string) {
+	fold := func(v string) string {
+		if conf.GeneralSettings().CaseSensitiveJobNames {
+			return v
+		}
+		return strings.ToLower(v)
+	}
+
+	seen := make(map[string]bool)
+	for _, t := range conf.Tasks() {
+		if seen[t.Name] || !strings.HasPrefix(fold(t.Name), fold(prefix)) {
+			continue
+		}
+		seen[t.Name] = true
+		names = append(names, t.Name)
+	}
+	return
+}
+
+// writeCompletion writes the completion script of the given shell to w.
+func writeCompletion(w io.Writer, shell, prog string) (err error) {
+	tmpl := ""
+	switch shell {
+	case completionBash:
+		tmpl = bashCompletion
+	case completionZsh:
+		tmpl = zshCompletion
+	case completionFish:
+		tmpl = fishCompletion
+	default:
+		return fmt.Errorf("%w: unsupported shell %q", errUsage, shell)
+	}
+
+	t, err := template.New(shell).Funcs(template.FuncMap{
+		"flagNames":   flagNames,
+		"valueFlags":  valueFlags,
+		"commandList": commandList,
+		"fishQuote":   fishQuote,
+		"join": func(words []string) string {
+			return strings.Join(words, " ")
+		},
+	}).Parse(tmpl)
+	if err != nil {
+		return
+	}
+	return t.Execute(w, newCompletionData(prog))
+}
+
+// newCompletionData collects the commands and flags of the cli.
+func newCompletionData(prog string) (data completionData) {
+	data = completionData{
+		Prog:   prog,
+		Func:   "_" + nonIdentReg.ReplaceAllString(prog, "_"),
+		Global: completionFlags("", newGlobalFlagSet(io.Discard)),
+	}
+	for _, c := range visibleCommands() {
+		fs, _ := newCommandFlagSet(c, io.Discard)
+		cc := completionCommand{
+			Name:    c.name,
+			Summary: c.summary,
+			Flags:   completionFlags(c.name, fs),
+			Tasks:   c.completeTasks,
+		}
+		if c.completeWords != nil {
+			cc.Words = c.completeWords()
+		}
+
+		// The flags of all subcommands are offered after the command itself.
+		seen := make(map[string]bool)
+		for _, sub := range c.subcommands {
+			sfs, _ := newCommandFlagSet(sub, io.Discard)
+			for _, f := range completionFlags(sub.name, sfs) {
+				if !seen[f.Name] {
+					seen[f.Name] = true
+					cc.Flags = append(cc.Flags, f)
+				}
+			}
+		}
+		data.Commands = append(data.Commands, cc)
+	}
+	return
+}
+
+// completionFlags converts all flags of fs, cmd is the name of the command the flags belong to.
+func completionFlags(cmd string, fs *flag.FlagSet) (flags []completionFlag) {
+	fs.VisitAll(func(f *flag.Flag) {
+		words, ok := completionFlagValues[cmd+"."+f.Name]
+		if !ok {
+			words = completionFlagValues[f.Name]
+		}
+		cf := completionFlag{
+			Name:   f.Name,
+			Usage:  f.Usage,
+			IsDir:  strings.HasSuffix(f.Name, "-dir"),
+			IsFile: strings.HasSuffix(f.Name, "-file"),
+			Words:  words,
+		}
+		bf, ok := f.Value.(interface{ IsBoolFlag() bool })
+		cf.IsBool = ok && bf.IsBoolFlag()
+		flags = append(flags, cf)
+	})
+	return
+}
+
+// flagNames returns the double dashed names of the given flags separated by spaces.
+func flagNames(flags []completionFlag) string {
+	names := make([]string, len(flags))
+	for i, f := range flags {
+		names[i] = "--" + f.Name
+	}
+	return strings.Join(names, " ")
+}
+
+// valueFlags returns all flags expecting a value.
+func valueFlags(flags []completionFlag) (value []completionFlag) {
+	for _, f := range flags {
+		if !f.IsBool {
+			value = append(value, f)
+		}
+	}
+	return
+}
+
+// commandList returns the names of the given commands separated by spaces.
+func commandList(commands []completionCommand) string {
+	names := make([]string, len(commands))
+	for i, c := range commands {
+		names[i] = c.Name
+	}
+	return strings.Join(names, " ")
+}
+
+// fishQuote quotes v as a single quoted fish string.
+func fishQuote(v string) string {
+	return "'" + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(v) + "'"
+}
+
+const bashCompletion = `# bash completion for {{.Prog}}
+# Load it with: source <({{.Prog}} completion bash)
+
+{{.Func}}_tasks() {
+	local i cfg=()
+	for ((i = 1; i < COMP_CWORD - 1; i++)); do
+		case "${COMP_WORDS[i]}" in
+		-config-dir | --config-dir | -config-file | --config-file | -profile | --profile)
+			cfg+=("${COMP_WORDS[i]}" "${COMP_WORDS[i+1]}")
+			;;
+		esac
+	done
+	mapfile -t -O "${#COMPREPLY[@]}" COMPREPLY < <({{.Prog}} "${cfg[@]}" __complete tasks "$1" 2>/dev/null)
+}
+
+{{.Func}}() {
+	local cur prev cmd i
+	cur="${COMP_WORDS[COMP_CWORD]}"
+	prev="${COMP_WORDS[COMP_CWORD-1]}"
+	COMPREPLY=()
+
+	for ((i = 1; i < COMP_CWORD; i++)); do
+		case "${COMP_WORDS[i]}" in
+{{- range valueFlags .Global}}
+		-{{.Name}} | --{{.Name}}) ((i++)) ;;
+{{- end}}
+		-*) ;;
+		*)
+			cmd="${COMP_WORDS[i]}"
+			break
+			;;
+		esac
+	done
+
+	case "$cmd" in
+	"")
+		case "$prev" in
+{{- range valueFlags .Global}}
+		-{{.Name}} | --{{.Name}})
+			{{if .IsDir}}mapfile -t COMPREPLY < <(compgen -d -- "$cur")
+			{{else if .IsFile}}mapfile -t COMPREPLY < <(compgen -f -- "$cur")
+			{{else if .Words}}mapfile -t COMPREPLY < <(compgen -W "{{join .Words}}" -- "$cur")
+			{{end}}return
+			;;
+{{- end}}
+		esac
+		if [[ "$cur" == -* ]]; then
+			mapfile -t COMPREPLY < <(compgen -W "{{flagNames .Global}}" -- "$cur")
+		else
+			mapfile -t COMPREPLY < <(compgen -W "{{commandList .Commands}}" -- "$cur")
+			{{.Func}}_tasks "$cur"
+		fi
+		;;
+{{- range .Commands}}
+	{{.Name}})
+		case "$prev" in
+{{- range valueFlags .Flags}}
+		-{{.Name}} | --{{.Name}})
+			{{if .IsDir}}mapfile -t COMPREPLY < <(compgen -d -- "$cur")
+			{{else if .IsFile}}mapfile -t COMPREPLY < <(compgen -f -- "$cur")
+			{{else if .Words}}mapfile -t COMPREPLY < <(compgen -W "{{join .Words}}" -- "$cur")
+			{{end}}return
+			;;
+{{- end}}
+		esac
+		if [[ "$cur" == -* ]]; then
+			mapfile -t COMPREPLY < <(compgen -W "{{flagNames .Flags}}" -- "$cur")
+		else
+			{{if .Tasks}}{{$.Func}}_tasks "$cur"{{else if .Words}}mapfile -t COMPREPLY < <(compgen -W "{{join .Words}}" -- "$cur"){{else}}:{{end}}
+		fi
+		;;
+{{- end}}
+	esac
+}
+
+complete -F {{.Func}} {{.Prog}}
+`
+
+const zshCompletion = `#compdef {{.Prog}}
+# zsh completion for {{.Prog}}
+# Load it with: source <({{.Prog}} completion zsh)
+
+{{.Func}}_tasks() {
+	local i
+	local -a cfg tasks
+	for ((i = 2; i < CURRENT - 1; i++)); do
+		case "${words[i]}" in
+		-config-dir | --config-dir | -config-file | --config-file | -profile | --profile)
+			cfg+=("${words[i]}" "${words[i+1]}")
+			;;
+		esac
+	done
+	tasks=(${(f)"$({{.Prog}} "${cfg[@]}" __complete tasks "${words[CURRENT]}" 2>/dev/null)"})
+	(( ${#tasks} )) && compadd -U -- "${tasks[@]}"
+}
+
+{{.Func}}() {
+	local cur prev cmd i
+	cur="${words[CURRENT]}"
+	prev="${words[CURRENT-1]}"
+
+	for ((i = 2; i < CURRENT; i++)); do
+		case "${words[i]}" in
+{{- range valueFlags .Global}}
+		-{{.Name}} | --{{.Name}}) ((i++)) ;;
+{{- end}}
+		-*) ;;
+		*)
+			cmd="${words[i]}"
+			break
+			;;
+		esac
+	done
+
+	case "$cmd" in
+	"")
+		case "$prev" in
+{{- range valueFlags .Global}}
+		-{{.Name}} | --{{.Name}})
+			{{if .IsDir}}_files -/
+			{{else if .IsFile}}_files
+			{{else if .Words}}compadd -- {{join .Words}}
+			{{end}}return
+			;;
+{{- end}}
+		esac
+		if [[ "$cur" == -* ]]; then
+			compadd -- {{flagNames .Global}}
+		else
+			compadd -- {{commandList .Commands}}
+			{{.Func}}_tasks
+		fi
+		;;
+{{- range .Commands}}
+	{{.Name}})
+		case "$prev" in
+{{- range valueFlags .Flags}}
+		-{{.Name}} | --{{.Name}})
+			{{if .IsDir}}_files -/
+			{{else if .IsFile}}_files
+			{{else if .Words}}compadd -- {{join .Words}}
+			{{end}}return
+			;;
+{{- end}}
+		esac
+		if [[ "$cur" == -* ]]; then
+			compadd -- {{flagNames .Flags}}
+		else
+			{{if .Tasks}}{{$.Func}}_tasks{{else if .Words}}compadd -- {{join .Words}}{{else}}:{{end}}
+		fi
+		;;
+{{- end}}
+	esac
+}
+
+compdef {{.Func}} {{.Prog}}
+`
+
+const fishCompletion = `# fish completion for {{.Prog}}
+# Load it with: {{.Prog}} completion fish | source
+
+function {{.Func}}_tasks
+	set -l cfg
+	set -l tokens (commandline -opc)
+	for i in (seq (count $tokens))
+		switch $tokens[$i]
+			case -config-dir --config-dir -config-file --config-file -profile --profile
+				if test $i -lt (count $tokens)
+					set -a cfg $tokens[$i] $tokens[(math $i + 1)]
+				end
+		end
+	end
+	{{.Prog}} $cfg __complete tasks (commandline -ct) 2>/dev/null
+end
+
+complete -c {{.Prog}} -f
+{{- range .Global}}
+complete -c {{$.Prog}} -n __fish_use_subcommand -o {{.Name}} -l {{.Name}}{{if .IsDir}} -x -a '(__fish_complete_directories)'{{else if .IsFile}} -r -F{{else if not .IsBool}} -x{{end}} -d {{fishQuote .Usage}}
+{{- end}}
+{{- range .Commands}}
+complete -c {{$.Prog}} -n __fish_use_subcommand -a {{.Name}} -d {{fishQuote .Summary}}
+{{- end}}
+complete -c {{.Prog}} -n __fish_use_subcommand -a '({{.Func}}_tasks)'
+{{- range .Commands}}
+{{- $cmd := .Name}}
+{{- range .Flags}}
+complete -c {{$.Prog}} -n '__fish_seen_subcommand_from {{$cmd}}' -o {{.Name}} -l {{.Name}}{{if .IsDir}} -x -a '(__fish_complete_directories)'{{else if .IsFile}} -r -F{{else if .Words}} -x -a {{fishQuote (join .Words)}}{{else if not .IsBool}} -x{{end}} -d {{fishQuote .Usage}}
+{{- end}}
+{{- if .Tasks}}
+complete -c {{$.Prog}} -n '__fish_seen_subcommand_from {{$cmd}}' -a '({{$.Func}}_tasks)'
+{{- else if .Words}}
+complete -c {{$.Prog}} -n '__fish_seen_subcommand_from {{$cmd}}' -a {{fishQuote (join .Words)}}
+{{- end}}
+{{- end}}
+`
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"WrapNGo/config"
+	"bytes"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const completionConfig = `Version: 1
+GeneralSettings:
+  GlobalCommand: echo
+  CaseSensitiveJobNames: %t
+Profiles:
+  staging:
+    GlobalDynamic:
+      Host: staging
+Tasks:
+  - Name: backup-db
+  - Name: Backup-Media
+  - Name: upload
+`
+
+// completionDuplicate defines a task of completionConfig a second time.
+const completionDuplicate = `Version: 1
+Tasks:
+  - Name: backup-db
+`
+
+func TestCompleteTaskNames(t *testing.T) {
+	tests := []struct {
+		name          string
+		caseSensitive bool
+		prefix        string
+		want          []string
+	}{
+		{name: "every task", want: []string{"backup-db", "Backup-Media", "upload"}},
+		{name: "prefix", prefix: "up", want: []string{"upload"}},
+		{name: "case-insensitive prefix", prefix: "BACK", want: []string{"backup-db", "Backup-Media"}},
+		{name: "case-sensitive prefix", caseSensitive: true, prefix: "Back", want: []string{"Backup-Media"}},
+		{name: "no match", prefix: "restore"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conf := reloadFiles(t, map[string]string{
+				"config.yaml": fmt.Sprintf(completionConfig, tt.caseSensitive),
+				"more.yaml":   completionDuplicate,
+			})
+			got := completeTaskNames(conf, tt.prefix)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("completeTaskNames(%q) = %q, want %q", tt.prefix, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompleteWithProfile(t *testing.T) {
+	tests := []struct {
+		name string
+		env  string
+		args []string
+		want string
+	}{
+		{name: "flag", args: []string{"-profile", "staging", "__complete", "tasks", "up"}, want: "upload\n"},
+		{name: "env", env: "staging", args: []string{"__complete", "tasks", "up"}, want: "upload\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setupConfigDir(t, map[string]string{
+				"config.yaml": fmt.Sprintf(completionConfig, false),
+			}, withProfile("")) // Resets the profile selected by -profile.
+			t.Setenv(config.EnvProfile, tt.env)
+
+			code := exitOK
+			out := captureOutput(t, func() {
+				code = execute(tt.args)
+			})
+			if code != exitOK || out != tt.want {
+				t.Errorf("execute(%q) = %d, %q, want %d, %q", tt.args, code, out, exitOK, tt.want)
+			}
+			if p := config.CurrentSnapshot().Profile(); p != "staging" {
+				t.Errorf("profile = %q, want %q", p, "staging")
+			}
+		})
+	}
+}
+
+func TestWriteCompletion(t *testing.T) {
+	tests := []struct {
+		shell string
+		want  []string
+	}{
+		{
+			shell: completionBash,
+			want: []string{
+				"complete -F _wrap_ngo wrap-ngo",
+				`wrap-ngo "${cfg[@]}" __complete tasks "$1"`,
+				"-config-dir | --config-dir | -config-file | --config-file | -profile | --profile)",
+				`compgen -W "table json yaml"`,
+			},
+		},
+		{
+			shell: completionZsh,
+			want: []string{
+				"#compdef wrap-ngo",
+				"compdef _wrap_ngo wrap-ngo",
+				`wrap-ngo "${cfg[@]}" __complete tasks "${words[CURRENT]}"`,
+				"-config-dir | --config-dir | -config-file | --config-file | -profile | --profile)",
+			},
+		},
+		{
+			shell: completionFish,
+			want: []string{
+				"complete -c wrap-ngo -n __fish_use_subcommand -a run",
+				"complete -c wrap-ngo -n '__fish_seen_subcommand_from run' -a '(_wrap_ngo_tasks)'",
+				"case -config-dir --config-dir -config-file --config-file -profile --profile",
+				"-x -a 'table json yaml'",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.shell, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			err := writeCompletion(buf, tt.shell, "wrap-ngo")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(buf.String(), want) {
+					t.Errorf("script does not contain %q", want)
+				}
+			}
+			if strings.Contains(buf.String(), "<no value>") {
+				t.Errorf("script contains unset values:\n%s", buf)
+			}
+		})
+	}
+
+	err := writeCompletion(&bytes.Buffer{}, "powershell", "wrap-ngo")
+	if !errors.Is(err, errUsage) {
+		t.Errorf("error = %v, want %v", err, errUsage)
+	}
+}
@@ -18,8 +18,14 @@ import (
 	"time"
 )
 
-// compress creates a tar gzip archive
-func compress(opts config.CompressionOptions) (output string, err error) {
+// The compressionPlan type contains the resolved values used to compress a path.
+type compressionPlan struct {
+	output   string
+	inMemory bool
+}
+
+// planCompression resolves the archive path and the compression mode without writing anything.
+func planCompression(opts config.CompressionOptions) (plan compressionPlan, err error) {
 	tm := time.Now()
 	_, err = os.Stat(opts.PathToCompress)
 	if err != nil {
@@ -64,12 +70,28 @@ func compress(opts config.CompressionOptions) (output string, err error) {
 		}
 	}
 
+	plan.inMemory = !exceeds
+	plan.output = opts.OutputPath
+	if plan.output == "" {
+		plan.output = filepath.Join(parent, dirName+"-"+t+".tar.gz")
+	}
+	return
+}
+
+// compress creates a tar gzip archive
+func compress(opts config.CompressionOptions) (output string, err error) {
+	plan, err := planCompression(opts)
+	if err != nil {
+		return
+	}
+	output = plan.output
+
 	// Remove file if already existing and overwrite flag is true.
 	removeOrErr := func() (err error) {
 		_, err = os.Stat(output)
 		if err == nil {
 			if !opts.OverwriteCompressed {
-				return errors.New(ErrArchAlreadyExists)
+				return ErrArchAlreadyExists
 			} else {
 				err = os.Remove(output)
 				if err != nil {
@@ -80,12 +102,7 @@ func compress(opts config.CompressionOptions) (output string, err error) {
 		return nil
 	}
 
-	output = opts.OutputPath
-	if output == "" {
-		output = filepath.Join(parent, dirName+"-"+t+".tar.gz")
-	}
-
-	if exceeds {
+	if !plan.inMemory {
 		err = removeOrErr()
 		if err != nil {
 			return "", err
@@ -0,0 +1,202 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+)
+
+const tasksKey = "Tasks"
+
+// ErrInvalidTasks is returned if the Tasks of a file is not a list.
+var ErrInvalidTasks = errors.New("Tasks is not a list")
+
+// AppendTask adds t to the Tasks of the given file, the format is chosen by the file extension.
+// Only the values set inside t are written. If the file does not exist, it is created containing only t.
+// Keys keep their order in json and yaml files, comments are not kept.
+func AppendTask(path string, t Task) (created bool, err error) {
+	f, err := formatOf(path)
+	if err != nil {
+		return
+	}
+	task, err := taskDocument(t, f)
+	if err != nil {
+		return
+	}
+
+	b, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		var out []byte
+		out, err = f.Marshal(newTaskDocument(f, task))
+		if err != nil {
+			return
+		}
+		return true, writeOutput(path, out, 0600, false)
+	}
+	if err != nil {
+		return
+	}
+
+	doc, err := f.decodeDoc(b)
+	if err != nil {
+		return
+	}
+	key := tasksKey
+	for k := range doc {
+		if !f.CaseSensitive && strings.EqualFold(k, tasksKey) {
+			key = k
+		}
+	}
+
+	var tasks []any
+	switch val := doc[key].(type) {
+	case nil:
+	case []any:
+		tasks = val
+	case []map[string]any:
+		for _, item := range val {
+			tasks = append(tasks, item)
+		}
+	default:
+		return false, fmt.Errorf("%s: %w", path, ErrInvalidTasks)
+	}
+	doc[key] = append(tasks, task)
+
+	var v any = doc
+	if f.parseNode != nil {
+		var root *node
+		root, err = f.parseNode(b)
+		if err != nil {
+			return
+		}
+		v = ordered(doc, root)
+	}
+	out, err := f.Marshal(v)
+	if err != nil {
+		return
+	}
+
+	// Never replace a working file by one which can not be loaded anymore.
+	var check Config
+	err = f.Unmarshal(out, &check)
+	if err != nil {
+		return false, fmt.Errorf("%s: the file would not be loadable anymore: %v", path, err)
+	}
+	stat, err := os.Stat(path)
+	if err != nil {
+		return
+	}
+	err = os.WriteFile(path, out, stat.Mode().Perm())
+	return
+}
+
+// TaskPreview returns t as it would be written by AppendTask into the given file.
+func TaskPreview(path string, t Task) (b []byte, err error) {
+	f, err := formatOf(path)
+	if err != nil {
+		return
+	}
+	task, err := taskDocument(t, f)
+	if err != nil {
+		return
+	}
+	return f.Marshal(map[string]any{tasksKey: []any{task}})
+}
+
+// newTaskDocument returns the content of a new file containing only the given task.
+func newTaskDocument(f *Format, task any) any {
+	tasks := []any{task}
+	if f.parseNode == nil {
+		return map[string]any{versionKey: CurrentVersion, tasksKey: tasks}
+	}
+	return orderedMap{
+		keys:   []string{versionKey, tasksKey},
+		values: map[string]any{versionKey: CurrentVersion, tasksKey: tasks},
+	}
+}
+
+// taskDocument returns the values set inside t as generic value which can be encoded in the format f.
+// The keys are ordered like the fields of Task if f keeps the order of keys.
+func taskDocument(t Task, f *Format) (v any, err error) {
+	b, err := json.Marshal(t)
+	if err != nil {
+		return
+	}
+	var doc map[string]any
+	err = json.Unmarshal(b, &doc)
+	if err != nil {
+		return
+	}
+	v = canonical(withoutZero(doc, reflect.TypeOf(Task{})), reflect.TypeOf(Task{}), f)
+	if f.parseNode == nil {
+		return
+	}
+
+	jf, err := formatByName(FormatJson)
+	if err != nil {
+		return
+	}
+	root, err := jf.parseNode(b)
+	if err != nil {
+		return
+	}
+	return ordered(v, root), nil
+}
+
+// withoutZero returns a copy of m, the decoded struct t, without the fields set to their zero value.
+// The values of maps (e.g. Dynamic) are kept as they are, only empty maps are removed.
+func withoutZero(m map[string]any, t reflect.Type) map[string]any {
+	pruned := make(map[string]any, len(m))
+	for k, v := range m {
+		var ft reflect.Type
+		sf, ok := t.FieldByName(k)
+		if ok {
+			ft = sf.Type
+		}
+
+		switch val := v.(type) {
+		case nil:
+			continue
+		case string:
+			if val == "" {
+				continue
+			}
+		case bool:
+			if !val {
+				continue
+			}
+		case float64:
+			if val == 0 {
+				continue
+			}
+		case map[string]any:
+			if ft != nil && ft.Kind() == reflect.Struct {
+				val = withoutZero(val, ft)
+			}
+			if len(val) == 0 {
+				continue
+			}
+			v = val
+		case []any:
+			if len(val) == 0 {
+				continue
+			}
+			if ft != nil && ft.Elem().Kind() == reflect.Struct {
+				items := make([]any, len(val))
+				for i, item := range val {
+					items[i] = item
+					im, ok := item.(map[string]any)
+					if ok {
+						items[i] = withoutZero(im, ft.Elem())
+					}
+				}
+				v = items
+			}
+		}
+		pruned[k] = v
+	}
+	return pruned
+}
@@ -0,0 +1,44 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestAppendTaskKeepsTasks(t *testing.T) {
+	const existing = `{
+	"Version": 1,
+	"Tasks": [
+		{
+			"Name": "backup",
+			"Command": "restic",
+			"Dynamic": {
+				"Id": 12345678901234567890,
+				"Ratio": 0.1
+			}
+		}
+	]
+}
+`
+	path := filepath.Join(t.TempDir(), "config.json")
+	err := os.WriteFile(path, []byte(existing), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	created, err := AppendTask(path, Task{Name: "upload", Command: "rclone"})
+	if err != nil || created {
+		t.Fatalf("AppendTask() = %t, %v, want the task to be appended", created, err)
+	}
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The existing task is written exactly as before, followed by the new one.
+	i := strings.Index(existing, "\t\t}\n\t]")
+	if !strings.HasPrefix(string(b), existing[:i+3]) || !strings.Contains(string(b[i:]), `"Name": "upload"`) {
+		t.Errorf("the existing task has been changed:\n%s", b)
+	}
+}
@@ -1,100 +1,229 @@
 package config
 
 import (
-	"encoding/json"
 	"errors"
 	"fmt"
 	"io/fs"
 	"log"
 	"os"
 	"path/filepath"
-	"runtime"
+	"reflect"
 	"strings"
 	"sync"
-
-	"gopkg.in/yaml.v3"
 )
 
 const (
-	jsonExtension   = ".json"
-	yamlExtension   = ".yaml"
 	fileBaseName    = "config"
-	fileNameJson    = fileBaseName + jsonExtension
-	fileNameYaml    = fileBaseName + yamlExtension
 	dirName         = "WrapNGo"
 	PlaceholderChar = "%"
 )
 
-var config = &Config{
-	GeneralSettings: GeneralSettings{},
-	GlobalDynamic:   map[string]any{},
-	Tasks:           []Task{},
-	Mutex:           &sync.Mutex{},
-}
+// ErrNotFound is returned if no configuration file exists.
+var ErrNotFound = errors.New("no config found")
+
+var (
+	// config contains the files added by Load, it is replaced by LoadAll.
+	config = newConfig()
+
+	// loadMux guards config.
+	loadMux sync.Mutex
+)
 
 type GeneralSettings struct {
-	GlobalCommand         string `json:"GlobalCommand" yaml:"GlobalCommand"`
-	Debug                 bool   `json:"Debug" yaml:"Debug"`
-	CaseSensitiveJobNames bool   `json:"CaseSensitiveJobNames" yaml:"CaseSensitiveJobNames"`
-	DateFormat            string `json:"DateFormat" yaml:"DateFormat"`
+	GlobalCommand         string `json:"GlobalCommand" yaml:"GlobalCommand" toml:"GlobalCommand"`
+	Debug                 bool   `json:"Debug" yaml:"Debug" toml:"Debug"`
+	CaseSensitiveJobNames bool   `json:"CaseSensitiveJobNames" yaml:"CaseSensitiveJobNames" toml:"CaseSensitiveJobNames"`
+	DateFormat            string `json:"DateFormat" yaml:"DateFormat" toml:"DateFormat"`
+
+	// DuplicateTaskPolicy defines how tasks with the same name are handled (see DuplicateTaskPolicies).
+	DuplicateTaskPolicy string `json:"DuplicateTaskPolicy" yaml:"DuplicateTaskPolicy" toml:"DuplicateTaskPolicy"`
+
+	// DisableConfigDirScan only loads the main config and its Include list instead of every file inside the config directory.
+	DisableConfigDirScan bool `json:"DisableConfigDirScan" yaml:"DisableConfigDirScan" toml:"DisableConfigDirScan"`
 }
 
 // The Operation type contains information for a single Task operation.
 // Each Task can contain up to 2 Tasks (Pre- and Post-operation).
 type Operation struct {
-	Enabled             bool     `json:"Enabled" yaml:"Enabled"`
-	StopIfUnsuccessful  bool     `json:"StopIfUnsuccessful" yaml:"StopIfUnsuccessful"`
-	SecondsUntilTimeout int      `json:"SecondsUntilTimeout" yaml:"SecondsUntilTimeout"`
-	IgnoreTimeout       bool     `json:"IgnoreTimeout" yaml:"IgnoreTimeout"`
-	CaptureStdOut       bool     `json:"CaptureStdOut" yaml:"CaptureStdOut"`
-	Command             string   `json:"Command" yaml:"Command"`
-	Arguments           []string `json:"Arguments" yaml:"Arguments"`
+	Enabled             bool     `json:"Enabled" yaml:"Enabled" toml:"Enabled"`
+	StopIfUnsuccessful  bool     `json:"StopIfUnsuccessful" yaml:"StopIfUnsuccessful" toml:"StopIfUnsuccessful"`
+	SecondsUntilTimeout int      `json:"SecondsUntilTimeout" yaml:"SecondsUntilTimeout" toml:"SecondsUntilTimeout"`
+	IgnoreTimeout       bool     `json:"IgnoreTimeout" yaml:"IgnoreTimeout" toml:"IgnoreTimeout"`
+	CaptureStdOut       bool     `json:"CaptureStdOut" yaml:"CaptureStdOut" toml:"CaptureStdOut"`
+	Command             string   `json:"Command" yaml:"Command" toml:"Command"`
+	Arguments           []string `json:"Arguments" yaml:"Arguments" toml:"Arguments"`
 }
 
 type CompressionOptions struct {
-	PathToCompress           string `json:"PathToCompress" yaml:"PathToCompress"`
-	OutputPath               string `json:"OutputPath" yaml:"OutputPath"`
-	InMemoryCompressionLimit string `json:"InMemoryCompressionLimit" yaml:"InMemoryCompressionLimit"`
-	OverwriteCompressed      bool   `json:"OverwriteCompressed" yaml:"OverwriteCompressed"`
-	RetainStructure          bool   `json:"RetainStructure" yaml:"RetainStructure"`
+	PathToCompress           string `json:"PathToCompress" yaml:"PathToCompress" toml:"PathToCompress"`
+	OutputPath               string `json:"OutputPath" yaml:"OutputPath" toml:"OutputPath"`
+	InMemoryCompressionLimit string `json:"InMemoryCompressionLimit" yaml:"InMemoryCompressionLimit" toml:"InMemoryCompressionLimit"`
+	OverwriteCompressed      bool   `json:"OverwriteCompressed" yaml:"OverwriteCompressed" toml:"OverwriteCompressed"`
+	RetainStructure          bool   `json:"RetainStructure" yaml:"RetainStructure" toml:"RetainStructure"`
 }
 
 // The Task type contains information for a single job.
 // The Config contains n Tasks.
 type Task struct {
-	Name                        string             `json:"Name" yaml:"Name"`
-	Command                     string             `json:"Command" yaml:"Command"`
-	Dynamic                     map[string]any     `json:"Dynamic" yaml:"Dynamic"`
-	Arguments                   []string           `json:"Arguments" yaml:"Arguments"`
-	StopIfUnsuccessful          bool               `json:"StopIfUnsuccessful" yaml:"StopIfUnsuccessful"`
-	RemovePathAfterJobCompletes string             `json:"RemovePathAfterJobCompletes" yaml:"RemovePathAfterJobCompletes"`
-	AllowParallelOperationsRun  bool               `json:"AllowParallelOperationsRun" yaml:"AllowParallelOperationsRun"`
-	Compression                 CompressionOptions `json:"Compression" yaml:"Compression"`
-	PreOperations               []Operation        `json:"PreOperations" yaml:"PreOperations"`
-	PostOperations              []Operation        `json:"PostOperations" yaml:"PostOperations"`
+	Name                        string             `json:"Name" yaml:"Name" toml:"Name"`
+	Extends                     string             `json:"Extends" yaml:"Extends" toml:"Extends"`
+	Abstract                    bool               `json:"Abstract" yaml:"Abstract" toml:"Abstract"`
+	Tags                        []string           `json:"Tags" yaml:"Tags" toml:"Tags"`
+	DependsOn                   []string           `json:"DependsOn" yaml:"DependsOn" toml:"DependsOn"`
+	Command                     string             `json:"Command" yaml:"Command" toml:"Command"`
+	Dynamic                     map[string]any     `json:"Dynamic" yaml:"Dynamic" toml:"Dynamic"`
+	Arguments                   []string           `json:"Arguments" yaml:"Arguments" toml:"Arguments"`
+	Environment                 map[string]string  `json:"Environment" yaml:"Environment" toml:"Environment"`
+	StopIfUnsuccessful          bool               `json:"StopIfUnsuccessful" yaml:"StopIfUnsuccessful" toml:"StopIfUnsuccessful"`
+	RemovePathAfterJobCompletes string             `json:"RemovePathAfterJobCompletes" yaml:"RemovePathAfterJobCompletes" toml:"RemovePathAfterJobCompletes"`
+	AllowParallelOperationsRun  bool               `json:"AllowParallelOperationsRun" yaml:"AllowParallelOperationsRun" toml:"AllowParallelOperationsRun"`
+	Compression                 CompressionOptions `json:"Compression" yaml:"Compression" toml:"Compression"`
+	PreOperations               []Operation        `json:"PreOperations" yaml:"PreOperations" toml:"PreOperations"`
+	PostOperations              []Operation        `json:"PostOperations" yaml:"PostOperations" toml:"PostOperations"`
+
+	// Source is the path of the file the task has been loaded from.
+	Source string `json:"-" yaml:"-" toml:"-"`
+
+	// Args contains the extra arguments given on the command line.
+	Args []string `json:"-" yaml:"-" toml:"-"`
+
+	// DateFormat is the format of the %Date% placeholder set by the Defaults of the task's file.
+	// If empty, GeneralSettings.DateFormat is used.
+	DateFormat string `json:"-" yaml:"-" toml:"-"`
+
+	// raw contains the values set inside the config file, used to merge the task with its base.
+	raw map[string]any
+
+	// defaults contains the Defaults of the file the task has been loaded from.
+	defaults FileDefaults
+}
+
+// The FileDefaults type contains the default values of all tasks inside a single config file.
+type FileDefaults struct {
+	// Command is used for the job and every operation without a Command instead of GeneralSettings.GlobalCommand.
+	Command string `json:"Command" yaml:"Command" toml:"Command"`
+
+	// DateFormat is used for the %Date% placeholder instead of GeneralSettings.DateFormat.
+	DateFormat string `json:"DateFormat" yaml:"DateFormat" toml:"DateFormat"`
+
+	// Dynamic contains the values added to the Dynamic of each task if not already set.
+	Dynamic map[string]any `json:"Dynamic" yaml:"Dynamic" toml:"Dynamic"`
+}
+
+// apply returns t with every unset value replaced by the defaults.
+func (d FileDefaults) apply(t Task) Task {
+	if t.Command == "" {
+		t.Command = d.Command
+	}
+	if t.DateFormat == "" {
+		t.DateFormat = d.DateFormat
+	}
+	t.PreOperations = d.applyOperations(t.PreOperations)
+	t.PostOperations = d.applyOperations(t.PostOperations)
+
+	if len(d.Dynamic) > 0 {
+		dynamic := make(map[string]any, len(d.Dynamic)+len(t.Dynamic))
+		for k, v := range d.Dynamic {
+			dynamic[k] = v
+		}
+		for k, v := range t.Dynamic {
+			dynamic[k] = v
+		}
+		t.Dynamic = dynamic
+	}
+	return t
+}
+
+// applyOperations returns a copy of ops using the default command for every operation without a Command.
+func (d FileDefaults) applyOperations(ops []Operation) []Operation {
+	if ops == nil || d.Command == "" {
+		return ops
+	}
+
+	applied := make([]Operation, len(ops))
+	copy(applied, ops)
+	for i := range applied {
+		if applied[i].Command == "" {
+			applied[i].Command = d.Command
+		}
+	}
+	return applied
 }
 
 // The Config type contains all the information used inside this project.
 type Config struct {
-	GeneralSettings GeneralSettings `json:"GeneralSettings" yaml:"GeneralSettings"`
-	GlobalDynamic   map[string]any  `json:"GlobalDynamic" yaml:"GlobalDynamic"`
-	Tasks           []Task          `json:"Tasks" yaml:"Tasks"`
-	*sync.Mutex     `json:"-" yaml:"-"`
+	// Version is the version of the config structure the file has been written for (see CurrentVersion).
+	Version int `json:"Version" yaml:"Version" toml:"Version"`
+
+	GeneralSettings GeneralSettings `json:"GeneralSettings" yaml:"GeneralSettings" toml:"GeneralSettings"`
+
+	// Defaults contains the default values of the tasks inside the same file.
+	Defaults FileDefaults `json:"Defaults" yaml:"Defaults" toml:"Defaults"`
+
+	// Include lists the files (or glob patterns) loaded after the main config in the given order.
+	// It is only read from the main config.
+	Include       []string       `json:"Include" yaml:"Include" toml:"Include"`
+	GlobalDynamic map[string]any `json:"GlobalDynamic" yaml:"GlobalDynamic" toml:"GlobalDynamic"`
+
+	// Profiles contains the named sets of values overlaid onto the config if selected (see SetProfile).
+	Profiles map[string]Profile `json:"Profiles" yaml:"Profiles" toml:"Profiles"`
+
+	Tasks       []Task `json:"Tasks" yaml:"Tasks" toml:"Tasks"`
+	*sync.Mutex `json:"-" yaml:"-" toml:"-"`
+
+	// settingsSet contains the names of the GeneralSettings set inside the file.
+	settingsSet map[string]bool
+
+	// settings contains the effective GeneralSettings together with their origin, set by LoadAll.
+	settings []Setting
+
+	// migrated contains the notes of the migrations applied while decoding the file.
+	migrated []string
+
+	// globalDynamicSources contains the file each value of GlobalDynamic has been loaded from.
+	globalDynamicSources map[string]string
+
+	// profileSources contains the file each value of Profiles has been loaded from, keyed by "<profile>.<path>".
+	profileSources map[string]string
+
+	// profile is the name of the applied profile, set by LoadAll.
+	profile string
+}
+
+// newConfig returns an empty config which files can be added to.
+func newConfig() *Config {
+	return &Config{
+		GlobalDynamic:        map[string]any{},
+		Tasks:                []Task{},
+		Mutex:                &sync.Mutex{},
+		Profiles:             map[string]Profile{},
+		globalDynamicSources: map[string]string{},
+		profileSources:       map[string]string{},
+	}
 }
 
 // defaultConfig defines the default configuration.
 func defaultConfig() *Config {
 	return &Config{
+		Version: CurrentVersion,
 		GeneralSettings: GeneralSettings{
-			GlobalCommand: "your-program-to-wrap",
-			DateFormat:    "YYYY-MM-DD_hh-mm-ss",
+			GlobalCommand:       "your-program-to-wrap",
+			DateFormat:          "YYYY-MM-DD_hh-mm-ss",
+			DuplicateTaskPolicy: DuplicateTaskWarn,
+		},
+		Defaults: FileDefaults{
+			Dynamic: map[string]any{},
 		},
+		Include: []string{},
 		GlobalDynamic: map[string]any{
 			"Description": "Here you can specify global dynamics to use as placeholders.",
 		},
+		Profiles: map[string]Profile{},
 		Tasks: []Task{
 			{
 				Name:               "ShortNameOfTask",
+				Tags:               []string{"Example"},
+				DependsOn:          []string{},
 				Command:            "Binary/command",
 				StopIfUnsuccessful: true,
 				Dynamic: map[string]any{
@@ -102,7 +231,8 @@ func defaultConfig() *Config {
 					"Source":      "Some/Source/Path",
 					"Destination": "Some/Destination/Path",
 				},
-				Arguments: []string{"--SomeArgument", "--another=Argument", "--Argument 3"},
+				Arguments:   []string{"--SomeArgument", "--another=Argument", "--Argument 3"},
+				Environment: map[string]string{},
 				Compression: CompressionOptions{
 					PathToCompress:           "",
 					OutputPath:               "",
@@ -144,22 +274,35 @@ func defaultConfig() *Config {
 	}
 }
 
-// NewConfig creates a new config.
-func NewConfig(overwrite, isYaml bool) (path string, created bool, err error) {
-	path, err = FullPath(isYaml)
-	if err != nil {
+// NewConfig creates a new config from the default template in the given format (see FormatNames).
+// If a main config file has been set via SetFile, its extension defines the format.
+func NewConfig(overwrite bool, format string) (path string, created bool, err error) {
+	return NewConfigFromTemplate(TemplateDefault, overwrite, format)
+}
+
+// NewConfigFromTemplate creates a new config from the given template (see TemplateNames) in the given format.
+// If a main config file has been set via SetFile, its extension defines the format.
+func NewConfigFromTemplate(template string, overwrite bool, format string) (path string, created bool, err error) {
+	tmpl, ok := templates[template]
+	if !ok {
+		err = fmt.Errorf("%w: %s", ErrUnknownTemplate, template)
 		return
 	}
 
-	// Create the folder which contains the config.
-	p, err := os.UserConfigDir()
+	path, err = FullPath(format)
+	if err != nil {
+		return
+	}
+	f, err := formatOf(path)
 	if err != nil {
 		return
 	}
-	p = filepath.Join(p, configDirPath())
+
+	// Create the folder which contains the config.
+	p := filepath.Dir(path)
 	_, err = os.Stat(p)
 	if errors.Is(err, os.ErrNotExist) {
-		err = os.Mkdir(p, 0700)
+		err = os.MkdirAll(p, 0700)
 		if err != nil {
 			return
 		}
@@ -167,20 +310,16 @@ func NewConfig(overwrite, isYaml bool) (path string, created bool, err error) {
 
 	if err == nil && overwrite {
 		err = os.Remove(path)
-		if err != nil {
-			log.Fatal("unable to remove config file")
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			err = fmt.Errorf("unable to remove config file: %v", err)
+			return
 		}
 	}
 
 	_, err = os.Stat(path)
 	if errors.Is(err, os.ErrNotExist) {
-		defConf := defaultConfig()
-		b := make([]byte, 0)
-		if isYaml {
-			b, err = yaml.Marshal(defConf)
-		} else {
-			b, err = json.MarshalIndent(defConf, "", "\t")
-		}
+		var b []byte
+		b, err = f.Marshal(tmpl())
 		if err != nil {
 			return
 		}
@@ -194,90 +333,294 @@ func NewConfig(overwrite, isYaml bool) (path string, created bool, err error) {
 	return
 }
 
-// LoadJson loads the given file to the in-memory config.
-func LoadJson(path string, isMain bool) (err error) {
-	var conf Config
-	err = conf.LoadInto(path, false)
+// Load loads the given file in any registered format to the in-memory config and publishes a new snapshot.
+// The tasks are added as they are, LoadAll resolves them after all files have been loaded.
+func Load(path string, isMain bool) (err error) {
+	loadMux.Lock()
+	defer loadMux.Unlock()
+	err = config.add(path, isMain)
 	if err != nil {
 		return
 	}
-
-	config.Lock()
-	defer config.Unlock()
-
-	config.Tasks = append(config.Tasks, conf.Tasks...)
-	addGlobalDynamics(conf.GlobalDynamic)
-	if isMain {
-		config.GeneralSettings = conf.GeneralSettings
-	}
+	publish(config)
 	return
 }
 
-// LoadYaml loads the given file to the in-memory config.
-func LoadYaml(path string, isMain bool) (err error) {
+// add decodes the given file and adds its tasks, GlobalDynamic values and profiles to c.
+// This implementation is not thread-safe.
+func (c *Config) add(path string, isMain bool) (err error) {
 	var conf Config
-	err = conf.LoadInto(path, true)
+	err = conf.LoadInto(path)
 	if err != nil {
 		return
 	}
 
-	config.Lock()
-	defer config.Unlock()
-
-	config.Tasks = append(config.Tasks, conf.Tasks...)
-	addGlobalDynamics(conf.GlobalDynamic)
+	for _, note := range conf.migrated {
+		log.Printf("%s: %s, run \"config migrate\" to update the file\n", path, note)
+	}
+	c.Tasks = append(c.Tasks, conf.Tasks...)
+	c.addGlobalDynamics(path, conf.GlobalDynamic)
+	c.addProfiles(path, conf.Profiles)
 	if isMain {
-		config.GeneralSettings = conf.GeneralSettings
+		c.GeneralSettings = conf.GeneralSettings
+		c.settingsSet = conf.settingsSet
 	}
 	return
 }
 
-// addGlobalDynamics adds all values of m to config if not already existing.
+// LoadJson loads the given file to the in-memory config.
+//
+// Deprecated: Use Load, the format is chosen by the file extension.
+func LoadJson(path string, isMain bool) (err error) {
+	return Load(path, isMain)
+}
+
+// LoadYaml loads the given file to the in-memory config.
+//
+// Deprecated: Use Load, the format is chosen by the file extension.
+func LoadYaml(path string, isMain bool) (err error) {
+	return Load(path, isMain)
+}
+
+// addGlobalDynamics adds all values of m loaded from path to c if not already existing.
 // This implementation is not thread-safe.
-func addGlobalDynamics(m map[string]any) {
+func (c *Config) addGlobalDynamics(path string, m map[string]any) {
 	if m == nil {
 		return
 	}
 
 	for k, v := range m {
-		_, ok := config.GlobalDynamic[k]
+		_, ok := c.GlobalDynamic[k]
 		if ok {
-			log.Printf("GlobalDynamic '%s' has already been set, skipping\n", k)
+			log.Printf("GlobalDynamic '%s' of %s has already been set in %s, skipping\n", k, path, c.globalDynamicSources[k])
 			continue
 		}
-		config.GlobalDynamic[k] = v
+		c.GlobalDynamic[k] = v
+		c.globalDynamicSources[k] = path
 	}
 }
 
-func (c *Config) LoadInto(path string, isYaml bool) (err error) {
+// LoadInto decodes the given file into c, the format is chosen by the file extension.
+// Files written for an older Version are migrated in memory first.
+// The Source of each decoded Task is set to path.
+func (c *Config) LoadInto(path string) (err error) {
+	f, err := formatOf(path)
+	if err != nil {
+		return
+	}
 	b, err := os.ReadFile(path)
 	if err != nil {
 		return
 	}
+	b, err = c.migrate(f, b)
+	if err != nil {
+		return
+	}
 	if c.Mutex == nil {
 		c.Mutex = &sync.Mutex{}
 	}
 	c.Lock()
 	defer c.Unlock()
 
-	if isYaml {
-		err = yaml.Unmarshal(b, &c)
+	var raw struct {
+		GeneralSettings map[string]any   `json:"GeneralSettings" yaml:"GeneralSettings" toml:"GeneralSettings"`
+		Tasks           []map[string]any `json:"Tasks" yaml:"Tasks" toml:"Tasks"`
+	}
+	err = f.Unmarshal(b, c)
+	if err == nil {
+		err = f.Unmarshal(b, &raw)
+	}
+	if err != nil {
 		return
 	}
-	err = json.Unmarshal(b, &c)
+
+	c.settingsSet = make(map[string]bool)
+	for k := range normalizeRaw(raw.GeneralSettings, reflect.TypeOf(GeneralSettings{}), f) {
+		c.settingsSet[k] = true
+	}
+	for i := range c.Tasks {
+		c.Tasks[i].Source = path
+		c.Tasks[i].defaults = c.Defaults
+		if i < len(raw.Tasks) {
+			c.Tasks[i].raw = normalizeRaw(raw.Tasks[i], reflect.TypeOf(Task{}), f)
+		}
+	}
 	return
 }
 
+// migrate upgrades b to CurrentVersion and stores the notes of the applied migrations.
+// b is only encoded again if a migration changed its structure.
+func (c *Config) migrate(f *Format, b []byte) (migrated []byte, err error) {
+	var doc map[string]any
+	err = f.Unmarshal(b, &doc)
+	if err != nil || doc == nil {
+		return b, err
+	}
+	_, c.migrated, err = migrate(doc)
+	if err != nil || len(c.migrated) == 0 {
+		return b, err
+	}
+	return f.Marshal(doc)
+}
+
+// The file type describes a single config file loaded by LoadAll.
+type file struct {
+	path   string
+	isMain bool
+
+	// otherMains contains the other possible main configs next to the main config, only set for the main config.
+	// They are not used as main config, their GeneralSettings are ignored.
+	otherMains []string
+}
+
+// LoadAll loads the main config and every other config inside the config directory (see Reload).
+// If only a main config file has been set (see SetFile), no other file will be loaded.
 func LoadAll() (err error) {
-	p, err := os.UserConfigDir()
+	_, err = Reload()
+	return
+}
+
+// Reload loads every config file into a new config and replaces the current snapshot with it at once.
+// Tasks which are already running keep the snapshot they have been started with.
+// If loading fails, the current snapshot is kept.
+func Reload() (s *Snapshot, err error) {
+	list, err := files()
+	if err != nil {
+		return
+	}
+
+	c := newConfig()
+	main := ""
+	for _, f := range list {
+		if f.isMain {
+			main = f.path
+			for _, other := range f.otherMains {
+				log.Printf("multiple main configs found, %s is used as main config instead of %s\n", filepath.Base(main), filepath.Base(other))
+			}
+		}
+		err = c.loadFile(f.path, f.isMain)
+		if err != nil {
+			return
+		}
+	}
+	if main == "" {
+		log.Printf("main config could not be found, please ensure one of %s is available\n", strings.Join(configFileNames(), ", "))
+	}
+
+	// The overrides need to be applied first, the settings affect how the tasks are resolved.
+	origins := make(map[string]Setting)
+	for name := range c.settingsSet {
+		origins[name] = Setting{Origin: OriginFile, Source: main}
+	}
+	profileOrigins, err := c.applyProfile()
+	if err != nil {
+		return
+	}
+	for name, o := range profileOrigins {
+		origins[name] = o
+	}
+	c.GeneralSettings, c.settings, err = applySettings(c.GeneralSettings, origins)
+	if err != nil {
+		return
+	}
+	err = c.resolveDuplicates()
+	if err != nil {
+		return
+	}
+	err = c.resolveTasks()
+	if err != nil {
+		return
+	}
+
+	loadMux.Lock()
+	defer loadMux.Unlock()
+	config = c
+	return publish(c), nil
+}
+
+// resolveTasks merges every task of c with its base task and removes the abstract tasks afterwards.
+// The Dynamic values of the applied profile are added last, the dependencies are checked once every task is resolved.
+// This implementation is not thread-safe.
+func (c *Config) resolveTasks() (err error) {
+	resolved, errs := resolveExtends(c.Tasks, c.GeneralSettings)
+	for i := range c.Tasks {
+		if errs[i] != nil {
+			return errs[i]
+		}
+	}
+	profile := c.Profiles[c.profile]
+	for i := range resolved {
+		resolved[i] = profile.applyTask(resolved[i].defaults.apply(resolved[i]), c.GeneralSettings)
+	}
+	errs = resolveDependencies(resolved, c.GeneralSettings)
+	for i := range resolved {
+		if errs[i] != nil {
+			return errs[i]
+		}
+	}
+	c.Tasks = removeAbstract(resolved)
+	return
+}
+
+// files returns every config file LoadAll loads in the order they are loaded.
+// The main config comes first, followed by the files of its Include list and
+// every other file inside the config directory in lexical order (unless disabled).
+func files() (list []file, err error) {
+	main := mainFile()
+	scan := main == "" || dirSet()
+	if main != "" {
+		_, err = os.Stat(main)
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("%w: %s", ErrNotFound, main)
+		}
+	}
+
+	p, err := Dir()
 	if err != nil {
 		return
 	}
+	if scan {
+		_, err = os.Stat(p)
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("%w: %s", ErrNotFound, p)
+		}
+	}
+	var otherMains []string
+	if main == "" {
+		main, otherMains = findMainFile(p)
+	}
+
+	seen := make(map[string]bool)
+	add := func(path string, isMain bool) {
+		if !seen[path] {
+			seen[path] = true
+			list = append(list, file{path: path, isMain: isMain})
+		}
+	}
+	if main != "" {
+		add(main, true)
+		list[0].otherMains = otherMains
+
+		var inc includes
+		inc, err = readIncludes(main)
+		if err != nil {
+			return nil, err
+		}
+		for _, path := range inc.paths {
+			add(path, false)
+		}
+
+		var s GeneralSettings
+		s, _, err = applySettings(GeneralSettings{DisableConfigDirScan: inc.disableScan}, nil)
+		if err != nil {
+			return nil, err
+		}
+		scan = scan && !s.DisableConfigDirScan
+	}
+	if !scan {
+		return
+	}
 
-	// Config dir to lower case if not Windows machine.
-	dir := configDirPath()
-	p = filepath.Join(p, dir)
-	mainFound := false
 	err = filepath.Walk(p, func(path string, info fs.FileInfo, err error) (wErr error) {
 		stat, wErr := os.Stat(path)
 		if wErr != nil {
@@ -288,64 +631,45 @@ func LoadAll() (err error) {
 			return
 		}
 
-		name := stat.Name()
-		nameLower := strings.ToLower(name)
-		isYaml := strings.HasSuffix(nameLower, yamlExtension)
-		isJson := strings.HasSuffix(nameLower, jsonExtension)
-		if !isYaml && !isJson {
-			return
-		}
-
-		if isYaml {
-			isMain := name == fileNameYaml
-			if isMain {
-				mainFound = true
-			}
-			wErr = LoadYaml(path, isMain)
-		} else {
-			isMain := name == fileNameJson
-			if isMain {
-				mainFound = true
-			}
-			wErr = LoadJson(path, isMain)
-		}
-		if wErr != nil {
-			return fmt.Errorf("unable to load %s: %v\n", name, wErr)
+		if isConfigFile(path) {
+			add(path, false)
 		}
 		return
 	})
 	if err != nil {
-		return
+		return nil, err
 	}
 
-	if !mainFound {
-		log.Println("main config could not be found, please ensure 'config.json' / 'config.yaml' is available")
+	if len(list) == 0 {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, p)
 	}
 	return
 }
 
-// FullPath returns the full path of the configuration file.
-func FullPath(isYaml bool) (p string, err error) {
-	p, err = os.UserConfigDir()
-	if err != nil {
-		return
-	}
-
-	// Config dir to lower case if not Windows machine.
-	dir := configDirPath()
-	if isYaml {
-		p = filepath.Join(p, dir, fileNameYaml)
-		return
+// findMainFile returns the path of the main config inside dir or an empty string if there is none.
+// If multiple exist, the format registered first is preferred (config.json, config.yaml, config.yml, config.toml),
+// the paths of the other ones are returned as others.
+func findMainFile(dir string) (path string, others []string) {
+	for _, name := range configFileNames() {
+		p := filepath.Join(dir, name)
+		stat, err := os.Stat(p)
+		if err != nil || stat.IsDir() {
+			continue
+		}
+		if path == "" {
+			path = p
+		} else {
+			others = append(others, p)
+		}
 	}
-	p = filepath.Join(p, dir, fileNameJson)
 	return
 }
 
-// configDirPath returns the dirName specifically formatted for the current OS.
-func configDirPath() (dir string) {
-	dir = dirName
-	if runtime.GOOS != "windows" {
-		dir = strings.ToLower(dir)
+// loadFile adds the given file to c.
+func (c *Config) loadFile(path string, isMain bool) (err error) {
+	err = c.add(path, isMain)
+	if err != nil {
+		return fmt.Errorf("unable to load %s: %v", filepath.Base(path), err)
 	}
 	return
 }
@@ -355,6 +679,8 @@ func formatPlaceholder(key string) string {
 	return PlaceholderChar + key + PlaceholderChar
 }
 
+// Current returns a deep copy of the current snapshot, changes to it do not affect the loaded config.
+// Use CurrentSnapshot to read single values without copying everything.
 func Current() Config {
-	return *config
+	return CurrentSnapshot().config()
 }
@@ -0,0 +1,53 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// The fixtureOption type changes the selection of setupConfigDir.
+type fixtureOption func(t *testing.T)
+
+// withProfile selects the given profile like the -profile flag.
+func withProfile(name string) fixtureOption {
+	return func(t *testing.T) {
+		SetProfile(name)
+		t.Cleanup(func() { SetProfile("") })
+	}
+}
+
+// setupConfigDir writes the given files into a new temporary config directory and selects it.
+// Names may contain subdirectories. Everything selected is reset when the test ends.
+func setupConfigDir(t *testing.T, files map[string]string, opts ...fixtureOption) (dir string) {
+	t.Helper()
+	dir = t.TempDir()
+	t.Setenv(EnvConfigDir, dir)
+	t.Setenv(EnvConfigFile, "")
+	t.Setenv(EnvProfile, "")
+	for _, opt := range opts {
+		opt(t)
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		err := os.MkdirAll(filepath.Dir(path), 0700)
+		if err == nil {
+			err = os.WriteFile(path, []byte(content), 0600)
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	return
+}
+
+// reloadFiles writes the given files like setupConfigDir and loads them, the test fails if they can not be loaded.
+func reloadFiles(t *testing.T, files map[string]string, opts ...fixtureOption) *Snapshot {
+	t.Helper()
+	setupConfigDir(t, files, opts...)
+	conf, err := Reload()
+	if err != nil {
+		t.Fatalf("unable to load the configs: %v", err)
+	}
+	return conf
+}
@@ -0,0 +1,286 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ErrOutputExists is returned if the output of a conversion or export already exists.
+var ErrOutputExists = errors.New("output already exists")
+
+var exportDateReg = regexp.MustCompile("(?i)" + regexp.QuoteMeta(formatPlaceholder("Date")))
+
+// The exportedConfig type is the single file written by Export.
+type exportedConfig struct {
+	Version         int             `json:"Version" yaml:"Version" toml:"Version"`
+	GeneralSettings GeneralSettings `json:"GeneralSettings" yaml:"GeneralSettings" toml:"GeneralSettings"`
+	GlobalDynamic   map[string]any  `json:"GlobalDynamic" yaml:"GlobalDynamic" toml:"GlobalDynamic"`
+	Tasks           []Task          `json:"Tasks" yaml:"Tasks" toml:"Tasks"`
+}
+
+// Convert decodes the given file and encodes it in the given format (see FormatNames).
+// Only the values set inside the file are written, the keys are renamed to the ones of the config structure
+// and keep their order if the source and the target format support it. Comments are not kept.
+func Convert(path, format string) (b []byte, err error) {
+	to, err := formatByName(format)
+	if err != nil {
+		return
+	}
+	from, err := formatOf(path)
+	if err != nil {
+		return
+	}
+	src, err := os.ReadFile(path)
+	if err != nil {
+		return
+	}
+
+	doc, err := from.decodeDoc(src)
+	if err != nil {
+		return
+	}
+
+	// Only formats with a node parser are encoded via orderedMap (see MarshalJSON and MarshalYAML).
+	var v any = doc
+	if from.parseNode != nil && to.parseNode != nil {
+		var root *node
+		root, err = from.parseNode(src)
+		if err != nil {
+			return
+		}
+		v = ordered(doc, root)
+	}
+	return to.Marshal(canonical(v, reflect.TypeOf(Config{}), from))
+}
+
+// ConvertedPath returns the path of the given file converted into format, the extension is replaced by the one of format.
+func ConvertedPath(path, format string) (converted string, err error) {
+	f, err := formatByName(format)
+	if err != nil {
+		return
+	}
+	return strings.TrimSuffix(path, filepath.Ext(path)) + f.Extensions[0], nil
+}
+
+// The ConversionResult type describes the conversion of a single file.
+type ConversionResult struct {
+	Path string
+
+	// Output is the path of the converted file.
+	Output string
+
+	// Backup is the path the original file has been renamed to.
+	Backup string
+
+	// Notes describe the changes which need to be applied manually.
+	Notes []string
+}
+
+// ConvertFile converts the given file into format and writes it to output (see Convert).
+// The original file is renamed to "<path>.bak" afterwards, otherwise the tasks would be loaded twice.
+// An existing output is only replaced if overwrite is set, an existing backup is never overwritten.
+func ConvertFile(path, format, output string, overwrite bool) (res ConversionResult, err error) {
+	res = ConversionResult{Path: path, Output: output, Backup: path + ".bak"}
+	if absPath(path) == absPath(output) {
+		return res, fmt.Errorf("%w: %s is the file to convert", ErrOutputExists, output)
+	}
+	b, err := Convert(path, format)
+	if err != nil {
+		return
+	}
+
+	_, err = os.Stat(res.Backup)
+	if err == nil {
+		return res, fmt.Errorf("%w: %s", ErrOutputExists, res.Backup)
+	}
+	stat, err := os.Stat(path)
+	if err != nil {
+		return
+	}
+	err = writeOutput(output, b, stat.Mode().Perm(), overwrite)
+	if err != nil {
+		return
+	}
+	err = os.Rename(path, res.Backup)
+	if err != nil {
+		return
+	}
+
+	// Included files are referenced by their name, the Include list can not be rewritten without losing its comments.
+	main, mErr := MainFile()
+	if mErr != nil || absPath(main) == absPath(path) {
+		return
+	}
+	inc, _ := readIncludes(main)
+	for _, p := range inc.paths {
+		if absPath(p) == absPath(path) {
+			res.Notes = append(res.Notes, fmt.Sprintf("%s is part of the Include list of %s, replace it with %s", filepath.Base(path), main, filepath.Base(output)))
+		}
+	}
+	return
+}
+
+// Export encodes the effective configuration of all loaded files as a single file in the given format.
+// It contains the effective GeneralSettings, the GlobalDynamic of all files and every task which is not abstract,
+// merged with its base tasks and the Defaults of its file. %Date% placeholders of tasks using
+// another DateFormat than GeneralSettings.DateFormat are replaced by %Date(<format>)%.
+// The values of the selected profile are part of the output, Profiles itself is not exported.
+func Export(format string) (b []byte, err error) {
+	f, err := formatByName(format)
+	if err != nil {
+		return
+	}
+
+	snap := CurrentSnapshot()
+	exported := exportedConfig{
+		Version:         CurrentVersion,
+		GeneralSettings: snap.settings,
+		GlobalDynamic:   canonical(snap.globalDynamic, nil, f).(map[string]any),
+		Tasks:           snap.Tasks(),
+	}
+	policy, err := snap.settings.duplicatePolicy()
+	if err != nil {
+		return
+	}
+	exported.GeneralSettings.DuplicateTaskPolicy = policy
+
+	for i, t := range exported.Tasks {
+		t.Extends = ""
+		if t.Dynamic != nil {
+			t.Dynamic = canonical(t.Dynamic, nil, f).(map[string]any)
+		}
+		if t.DateFormat != "" && t.DateFormat != snap.settings.DateFormat {
+			t, err = withDateFormat(t)
+			if err != nil {
+				return
+			}
+		}
+		exported.Tasks[i] = t
+	}
+	return f.Marshal(exported)
+}
+
+// WriteOutput writes b to the given file, an existing file is only replaced if overwrite is set.
+func WriteOutput(output string, b []byte, overwrite bool) error {
+	return writeOutput(output, b, 0600, overwrite)
+}
+
+// writeOutput writes b to the given file with the given permissions.
+func writeOutput(output string, b []byte, perm os.FileMode, overwrite bool) (err error) {
+	flag := os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+	if !overwrite {
+		flag |= os.O_EXCL
+	}
+	f, err := os.OpenFile(output, flag, perm)
+	if errors.Is(err, os.ErrExist) {
+		return fmt.Errorf("%w: %s", ErrOutputExists, output)
+	}
+	if err != nil {
+		return
+	}
+	_, err = f.Write(b)
+	cErr := f.Close()
+	if err == nil {
+		err = cErr
+	}
+	return
+}
+
+// withDateFormat returns t with every %Date% placeholder replaced by %Date(<t.DateFormat>)%.
+func withDateFormat(t Task) (replaced Task, err error) {
+	b, err := json.Marshal(t)
+	if err != nil {
+		return
+	}
+	with, err := json.Marshal(formatPlaceholder("Date(" + t.DateFormat + ")"))
+	if err != nil {
+		return
+	}
+	b = exportDateReg.ReplaceAll(b, with[1:len(with)-1])
+	err = json.Unmarshal(b, &replaced)
+	if err != nil {
+		return
+	}
+	replaced.Source, replaced.Args, replaced.DateFormat = t.Source, t.Args, t.DateFormat
+	return
+}
+
+// canonical renames the keys of v to the keys of the struct tags of t, nested structs, lists and maps of structs included.
+// Keys which do not belong to a field are kept as they are. Whole numbers decoded as float64 are converted into int64,
+// otherwise a format with distinct integer types would write 3 as 3.0. json.Number values are converted into int64,
+// uint64 if they are too large, or float64 if they are no whole numbers.
+func canonical(v any, t reflect.Type, f *Format) any {
+	for t != nil && (t.Kind() == reflect.Pointer || t.Kind() == reflect.Slice) {
+		t = t.Elem()
+	}
+
+	// The keys of a map are kept, only its values are renamed (e.g. the ones of Profiles).
+	var elem reflect.Type
+	if t != nil && t.Kind() == reflect.Map {
+		elem = t.Elem()
+	}
+	if t != nil && t.Kind() != reflect.Struct {
+		t = nil
+	}
+
+	rename := func(k string) (string, reflect.Type) {
+		if t == nil {
+			return k, elem
+		}
+		sf, _, ok := lookupField(t, k, f)
+		if !ok {
+			return k, nil
+		}
+		return sf.Name, sf.Type
+	}
+
+	switch val := v.(type) {
+	case map[string]any:
+		m := make(map[string]any, len(val))
+		for k, item := range val {
+			name, ft := rename(k)
+			m[name] = canonical(item, ft, f)
+		}
+		return m
+	case orderedMap:
+		m := orderedMap{keys: make([]string, len(val.keys)), values: make(map[string]any, len(val.values))}
+		for i, k := range val.keys {
+			name, ft := rename(k)
+			m.keys[i] = name
+			m.values[name] = canonical(val.values[k], ft, f)
+		}
+		return m
+	case []any:
+		items := make([]any, len(val))
+		for i, item := range val {
+			items[i] = canonical(item, t, f)
+		}
+		return items
+	case float64:
+		if val == math.Trunc(val) && math.Abs(val) < 1<<53 {
+			return int64(val)
+		}
+	case json.Number:
+		i, err := val.Int64()
+		if err == nil {
+			return i
+		}
+		u, err := strconv.ParseUint(val.String(), 10, 64)
+		if err == nil {
+			return u
+		}
+		fl, err := val.Float64()
+		if err == nil {
+			return canonical(fl, t, f)
+		}
+	}
+	return v
+}
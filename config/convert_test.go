@@ -0,0 +1,38 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestConvertNumbers(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	err := os.WriteFile(path, []byte(`{"Version": 1, "GlobalDynamic": {"Id": 1234567890123456789, "Big": 12345678901234567890, "Ratio": 0.5, "Count": 3}}`), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		format string
+		want   []string
+	}{
+		{format: FormatYaml, want: []string{"Id: 1234567890123456789", "Big: 12345678901234567890", "Ratio: 0.5", "Count: 3"}},
+		{format: FormatToml, want: []string{"Id = 1234567890123456789", "Ratio = 0.5", "Count = 3"}},
+		{format: FormatJson, want: []string{`"Id": 1234567890123456789`, `"Big": 12345678901234567890`, `"Ratio": 0.5`, `"Count": 3`}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			b, err := Convert(path, tt.format)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(string(b), want) {
+					t.Errorf("output does not contain %q:\n%s", want, b)
+				}
+			}
+		})
+	}
+}
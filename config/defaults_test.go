@@ -0,0 +1,93 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFileDefaults(t *testing.T) {
+	conf := reloadFiles(t, map[string]string{
+		"config.yaml": `Version: 1
+GeneralSettings:
+  GlobalCommand: global
+  DateFormat: YYYY
+Tasks:
+  - Name: main
+    PreOperations:
+      - Enabled: true
+`,
+		"restic.yaml": `Version: 1
+Defaults:
+  Command: restic
+  DateFormat: YYYY-MM-DD
+  Dynamic:
+    Repo: /backup
+    Host: default
+Tasks:
+  - Name: defaults
+    PreOperations:
+      - Enabled: true
+  - Name: own
+    Command: own
+    Dynamic:
+      Host: own
+    PreOperations:
+      - Command: op
+        Enabled: true
+`,
+	})
+
+	tests := []struct {
+		name       string
+		task       string
+		command    string
+		dateFormat string
+		dynamic    map[string]any
+		opCommand  string
+	}{
+		{
+			name:      "main config is not affected",
+			task:      "main",
+			command:   "",
+			opCommand: "",
+		},
+		{
+			name:       "defaults fill unset values",
+			task:       "defaults",
+			command:    "restic",
+			dateFormat: "YYYY-MM-DD",
+			dynamic:    map[string]any{"Repo": "/backup", "Host": "default"},
+			opCommand:  "restic",
+		},
+		{
+			name:       "values of the task take precedence",
+			task:       "own",
+			command:    "own",
+			dateFormat: "YYYY-MM-DD",
+			dynamic:    map[string]any{"Repo": "/backup", "Host": "own"},
+			opCommand:  "op",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			task, ok := conf.Task(tt.task)
+			if !ok {
+				t.Fatalf("task %q not found", tt.task)
+			}
+			if task.Command != tt.command {
+				t.Errorf("Command = %q, want %q", task.Command, tt.command)
+			}
+			if task.DateFormat != tt.dateFormat {
+				t.Errorf("DateFormat = %q, want %q", task.DateFormat, tt.dateFormat)
+			}
+			if len(task.Dynamic) > 0 || len(tt.dynamic) > 0 {
+				if !reflect.DeepEqual(task.Dynamic, tt.dynamic) {
+					t.Errorf("Dynamic = %v, want %v", task.Dynamic, tt.dynamic)
+				}
+			}
+			if task.PreOperations[0].Command != tt.opCommand {
+				t.Errorf("operation Command = %q, want %q", task.PreOperations[0].Command, tt.opCommand)
+			}
+		})
+	}
+}
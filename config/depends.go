@@ -0,0 +1,106 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrInvalidDependsOn is returned if a dependency of Task.DependsOn can not be resolved.
+var ErrInvalidDependsOn = errors.New("invalid DependsOn")
+
+// The dependencyCycleError type is returned if tasks depend on each other.
+type dependencyCycleError struct {
+	chain []string
+}
+
+func (e *dependencyCycleError) Error() string {
+	return fmt.Sprintf("%v: tasks depend on each other (%s)", ErrInvalidDependsOn, strings.Join(e.chain, " -> "))
+}
+
+func (e *dependencyCycleError) Unwrap() error {
+	return ErrInvalidDependsOn
+}
+
+// resolveDependencies checks the DependsOn of every concrete task.
+// Each dependency needs to name at least one concrete task, a dependency matching multiple tasks depends on all of them.
+// errs contains the error of each task with an unknown dependency or being part of a cycle,
+// the indices are the ones of tasks. Abstract tasks are checked through the tasks extending them.
+func resolveDependencies(tasks []Task, s GeneralSettings) (errs map[int]error) {
+	errs = make(map[int]error)
+	byName := make(map[string][]int)
+	for i, t := range tasks {
+		key := s.taskKey(t.Name)
+		byName[key] = append(byName[key], i)
+	}
+
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := make([]int, len(tasks))
+	stack := make([]int, 0)
+	var visit func(i int)
+	visit = func(i int) {
+		state[i] = visiting
+		stack = append(stack, i)
+		defer func() {
+			stack = stack[:len(stack)-1]
+			state[i] = visited
+		}()
+
+		t := tasks[i]
+		for _, name := range t.DependsOn {
+			deps := byName[s.taskKey(name)]
+			switch {
+			case strings.TrimSpace(name) == "":
+				setError(errs, i, fmt.Errorf("%w: task \"%s\" contains an empty dependency", ErrInvalidDependsOn, t.Name))
+				continue
+			case len(deps) == 0:
+				setError(errs, i, fmt.Errorf("%w: dependency \"%s\" of task \"%s\" does not exist", ErrInvalidDependsOn, name, t.Name))
+				continue
+			}
+
+			for _, j := range deps {
+				if tasks[j].Abstract {
+					setError(errs, i, fmt.Errorf("%w: dependency \"%s\" of task \"%s\" is abstract and never run", ErrInvalidDependsOn, name, t.Name))
+					continue
+				}
+				switch state[j] {
+				case unvisited:
+					visit(j)
+				case visiting:
+					cycle := &dependencyCycleError{}
+					for k := len(stack) - 1; k >= 0; k-- {
+						if stack[k] == j {
+							for _, m := range stack[k:] {
+								cycle.chain = append(cycle.chain, tasks[m].Name)
+							}
+							for _, m := range stack[k:] {
+								setError(errs, m, cycle)
+							}
+							break
+						}
+					}
+					cycle.chain = append(cycle.chain, tasks[j].Name)
+				}
+			}
+		}
+	}
+
+	for i, t := range tasks {
+		if state[i] == unvisited && !t.Abstract {
+			visit(i)
+		}
+	}
+	return
+}
+
+// setError sets the error of the task i unless it already has one.
+func setError(errs map[int]error, i int, err error) {
+	_, ok := errs[i]
+	if !ok {
+		errs[i] = err
+	}
+}
@@ -0,0 +1,127 @@
+package config
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestResolveDependencies(t *testing.T) {
+	task := func(name string, deps ...string) Task {
+		return Task{Name: name, DependsOn: deps}
+	}
+	abstract := func(name string, deps ...string) Task {
+		return Task{Name: name, Abstract: true, DependsOn: deps}
+	}
+
+	tests := []struct {
+		name          string
+		tasks         []Task
+		caseSensitive bool
+
+		// errs contains the expected error message of each failing task.
+		errs  map[int]string
+		cycle bool
+	}{
+		{
+			name:  "no dependencies",
+			tasks: []Task{task("a"), task("b")},
+		},
+		{
+			name:  "diamond",
+			tasks: []Task{task("upload", "db dump", "app dump"), task("db dump", "mount"), task("app dump", "mount"), task("mount")},
+		},
+		{
+			name:  "case-insensitive names",
+			tasks: []Task{task("a", "B"), task("b")},
+		},
+		{
+			name:          "case-sensitive names",
+			tasks:         []Task{task("a", "B"), task("b")},
+			caseSensitive: true,
+			errs:          map[int]string{0: `dependency "B" of task "a" does not exist`},
+		},
+		{
+			name:  "duplicate names",
+			tasks: []Task{task("a", "b"), task("b"), task("b")},
+		},
+		{
+			name:  "unknown dependency",
+			tasks: []Task{task("a", "missing"), task("b", "a")},
+			errs:  map[int]string{0: `dependency "missing" of task "a" does not exist`},
+		},
+		{
+			name:  "empty dependency",
+			tasks: []Task{task("a", " ")},
+			errs:  map[int]string{0: `task "a" contains an empty dependency`},
+		},
+		{
+			name:  "abstract dependency",
+			tasks: []Task{task("a", "base"), abstract("base")},
+			errs:  map[int]string{0: `dependency "base" of task "a" is abstract and never run`},
+		},
+		{
+			name:  "dependencies of abstract tasks are not checked",
+			tasks: []Task{abstract("base", "missing")},
+		},
+		{
+			name:  "self",
+			tasks: []Task{task("a", "a")},
+			errs:  map[int]string{0: "tasks depend on each other (a -> a)"},
+			cycle: true,
+		},
+		{
+			name:  "cycle",
+			tasks: []Task{task("x", "a"), task("a", "b"), task("b", "c"), task("c", "a")},
+			errs: map[int]string{
+				1: "tasks depend on each other (a -> b -> c -> a)",
+				2: "tasks depend on each other (a -> b -> c -> a)",
+				3: "tasks depend on each other (a -> b -> c -> a)",
+			},
+			cycle: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs := resolveDependencies(tt.tasks, GeneralSettings{CaseSensitiveJobNames: tt.caseSensitive})
+			got := make(map[int]string)
+			for i, err := range errs {
+				if !errors.Is(err, ErrInvalidDependsOn) {
+					t.Errorf("error of task #%d = %v, want %v", i, err, ErrInvalidDependsOn)
+				}
+				var cycleErr *dependencyCycleError
+				if errors.As(err, &cycleErr) != tt.cycle {
+					t.Errorf("error of task #%d = %v, cycle %t expected", i, err, tt.cycle)
+				}
+				got[i] = strings.TrimPrefix(err.Error(), ErrInvalidDependsOn.Error()+": ")
+			}
+			if len(tt.errs) == 0 {
+				tt.errs = map[int]string{}
+			}
+			if !reflect.DeepEqual(got, tt.errs) {
+				t.Errorf("errors = %v, want %v", got, tt.errs)
+			}
+		})
+	}
+}
+
+func TestResolveTasksRejectsDependencyCycles(t *testing.T) {
+	c := loadTestConfig(t, "config.yaml", `
+Tasks:
+  - Name: base
+    Abstract: true
+    DependsOn: [b]
+  - Name: a
+    Extends: base
+  - Name: b
+    DependsOn: [a]
+`)
+	err := c.resolveTasks()
+	if !errors.Is(err, ErrInvalidDependsOn) {
+		t.Fatalf("error = %v, want %v", err, ErrInvalidDependsOn)
+	}
+	if !strings.Contains(err.Error(), "(a -> b -> a)") {
+		t.Errorf("error = %v, want the inherited cycle", err)
+	}
+}
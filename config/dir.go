@@ -0,0 +1,117 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+const (
+	// EnvConfigDir is the environment variable overriding the config directory.
+	EnvConfigDir = "WRAPNGO_CONFIG_DIR"
+
+	// EnvConfigFile is the environment variable overriding the main config file.
+	EnvConfigFile = "WRAPNGO_CONFIG_FILE"
+)
+
+var (
+	dirOverride  string
+	fileOverride string
+)
+
+// SetDir overrides the directory containing the configuration files.
+// It takes precedence over the WRAPNGO_CONFIG_DIR environment variable.
+func SetDir(dir string) {
+	dirOverride = absPath(dir)
+}
+
+// SetFile overrides the main configuration file.
+// It takes precedence over the WRAPNGO_CONFIG_FILE environment variable.
+func SetFile(path string) {
+	fileOverride = absPath(path)
+}
+
+// Dir returns the directory containing the configuration files.
+// The directory is resolved in the following order:
+// SetDir, WRAPNGO_CONFIG_DIR, the directory of the main config file and the user's config directory.
+func Dir() (dir string, err error) {
+	if dirSet() {
+		if dirOverride != "" {
+			return dirOverride, nil
+		}
+		return absPath(os.Getenv(EnvConfigDir)), nil
+	}
+
+	main := mainFile()
+	if main != "" {
+		return filepath.Dir(main), nil
+	}
+
+	dir, err = os.UserConfigDir()
+	if err != nil {
+		return
+	}
+
+	// Config dir to lower case if not Windows machine.
+	dir = filepath.Join(dir, configDirPath())
+	return
+}
+
+// FullPath returns the full path of the main configuration file in the given format (see FormatNames).
+// If a main config file has been set, format will be ignored.
+func FullPath(format string) (p string, err error) {
+	main := mainFile()
+	if main != "" {
+		return main, nil
+	}
+
+	if format == "" {
+		format = FormatJson
+	}
+	f, err := formatByName(format)
+	if err != nil {
+		return
+	}
+	p, err = Dir()
+	if err != nil {
+		return
+	}
+	p = filepath.Join(p, f.fileName())
+	return
+}
+
+// dirSet returns whether the config directory has been overridden.
+func dirSet() bool {
+	return dirOverride != "" || os.Getenv(EnvConfigDir) != ""
+}
+
+// mainFile returns the overridden main config file or an empty string if unset.
+func mainFile() string {
+	if fileOverride != "" {
+		return fileOverride
+	}
+	return absPath(os.Getenv(EnvConfigFile))
+}
+
+// absPath returns the absolute representation of path.
+// Empty paths and paths which cannot be resolved are returned unchanged.
+func absPath(path string) string {
+	if path == "" {
+		return path
+	}
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return path
+	}
+	return abs
+}
+
+// configDirPath returns the dirName specifically formatted for the current OS.
+func configDirPath() (dir string) {
+	dir = dirName
+	if runtime.GOOS != "windows" {
+		dir = strings.ToLower(dir)
+	}
+	return
+}
@@ -0,0 +1,107 @@
+package config
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestDir(t *testing.T) {
+	tmp := t.TempDir()
+	flagDir := filepath.Join(tmp, "flag")
+	envDir := filepath.Join(tmp, "env")
+	mainDir := filepath.Join(tmp, "main")
+	tests := []struct {
+		name     string
+		flagDir  string
+		envDir   string
+		flagFile string
+		envFile  string
+		wantDir  string
+		wantFile string
+	}{
+		{
+			name:     "flag",
+			flagDir:  flagDir,
+			wantDir:  flagDir,
+			wantFile: filepath.Join(flagDir, "config.json"),
+		},
+		{
+			name:     "env",
+			envDir:   envDir,
+			wantDir:  envDir,
+			wantFile: filepath.Join(envDir, "config.json"),
+		},
+		{
+			name:     "flag takes precedence over env",
+			flagDir:  flagDir,
+			envDir:   envDir,
+			wantDir:  flagDir,
+			wantFile: filepath.Join(flagDir, "config.json"),
+		},
+		{
+			name:     "main file sets the dir",
+			envFile:  filepath.Join(mainDir, "wrapngo.yaml"),
+			wantDir:  mainDir,
+			wantFile: filepath.Join(mainDir, "wrapngo.yaml"),
+		},
+		{
+			name:     "main file flag takes precedence over env",
+			flagFile: filepath.Join(mainDir, "flag.yaml"),
+			envFile:  filepath.Join(mainDir, "env.yaml"),
+			wantDir:  mainDir,
+			wantFile: filepath.Join(mainDir, "flag.yaml"),
+		},
+		{
+			name:     "dir takes precedence over the dir of the main file",
+			envDir:   envDir,
+			envFile:  filepath.Join(mainDir, "wrapngo.yaml"),
+			wantDir:  envDir,
+			wantFile: filepath.Join(mainDir, "wrapngo.yaml"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				dirOverride = ""
+				fileOverride = ""
+			}()
+			t.Setenv(EnvConfigDir, tt.envDir)
+			t.Setenv(EnvConfigFile, tt.envFile)
+			if tt.flagDir != "" {
+				SetDir(tt.flagDir)
+			}
+			if tt.flagFile != "" {
+				SetFile(tt.flagFile)
+			}
+
+			dir, err := Dir()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if dir != tt.wantDir {
+				t.Errorf("Dir() = %q, want %q", dir, tt.wantDir)
+			}
+			file, err := FullPath(FormatJson)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if file != tt.wantFile {
+				t.Errorf("FullPath() = %q, want %q", file, tt.wantFile)
+			}
+		})
+	}
+}
+
+func TestSetDirRelative(t *testing.T) {
+	defer func() {
+		dirOverride = ""
+	}()
+	SetDir("relative")
+	dir, err := Dir()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !filepath.IsAbs(dir) || filepath.Base(dir) != "relative" {
+		t.Errorf("Dir() = %q, want the absolute path of \"relative\"", dir)
+	}
+}
@@ -0,0 +1,123 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+)
+
+const (
+	// DuplicateTaskError aborts loading the config if multiple tasks share the same name.
+	DuplicateTaskError = "error"
+
+	// DuplicateTaskWarn keeps every task with the same name and logs a warning.
+	// This is the default policy.
+	DuplicateTaskWarn = "warn-and-run-all"
+
+	// DuplicateTaskLastWins only keeps the task loaded last.
+	DuplicateTaskLastWins = "last-wins"
+
+	// DuplicateTaskFirstWins only keeps the task loaded first.
+	DuplicateTaskFirstWins = "first-wins"
+)
+
+var (
+	// ErrDuplicateTask is returned if multiple tasks share the same name and DuplicateTaskError is used.
+	ErrDuplicateTask = errors.New("duplicate task")
+
+	// ErrUnknownPolicy is returned if GeneralSettings.DuplicateTaskPolicy contains an unknown value.
+	ErrUnknownPolicy = errors.New("unknown DuplicateTaskPolicy")
+)
+
+// DuplicateTaskPolicies returns all valid values of GeneralSettings.DuplicateTaskPolicy.
+func DuplicateTaskPolicies() []string {
+	return []string{DuplicateTaskError, DuplicateTaskWarn, DuplicateTaskLastWins, DuplicateTaskFirstWins}
+}
+
+// duplicatePolicy returns the effective policy of s.
+func (s GeneralSettings) duplicatePolicy() (policy string, err error) {
+	policy = strings.ToLower(s.DuplicateTaskPolicy)
+	if policy == "" {
+		return DuplicateTaskWarn, nil
+	}
+	for _, p := range DuplicateTaskPolicies() {
+		if p == policy {
+			return
+		}
+	}
+	return "", fmt.Errorf("%w %q, expected one of %s", ErrUnknownPolicy, s.DuplicateTaskPolicy, strings.Join(DuplicateTaskPolicies(), ", "))
+}
+
+// taskKey returns the key used to compare task names, honoring CaseSensitiveJobNames.
+func (s GeneralSettings) taskKey(name string) string {
+	if s.CaseSensitiveJobNames {
+		return name
+	}
+	return strings.ToLower(name)
+}
+
+// resolveDuplicates applies GeneralSettings.DuplicateTaskPolicy to the tasks of c.
+// This implementation is not thread-safe.
+func (c *Config) resolveDuplicates() (err error) {
+	policy, err := c.GeneralSettings.duplicatePolicy()
+	if err != nil {
+		return
+	}
+
+	drop := duplicates(c.Tasks, c.GeneralSettings, policy, func(prev, t Task) {
+		switch policy {
+		case DuplicateTaskError:
+			if err == nil {
+				err = fmt.Errorf("%w: task \"%s\" is defined in %s and %s", ErrDuplicateTask, t.Name, prev.Source, t.Source)
+			}
+		case DuplicateTaskWarn:
+			log.Printf("task \"%s\" is defined in %s and %s, both will be run\n", t.Name, prev.Source, t.Source)
+		case DuplicateTaskLastWins:
+			log.Printf("task \"%s\" from %s overrides the one from %s\n", t.Name, t.Source, prev.Source)
+		case DuplicateTaskFirstWins:
+			log.Printf("task \"%s\" from %s is ignored, it is already defined in %s\n", t.Name, t.Source, prev.Source)
+		}
+	})
+	if err != nil {
+		return
+	}
+	c.Tasks = withoutIndices(c.Tasks, drop)
+	return
+}
+
+// duplicates calls found for every task whose name has already been used by a previous task.
+// It returns the indices of the tasks which are dropped by the given policy.
+func duplicates(tasks []Task, s GeneralSettings, policy string, found func(prev, t Task)) (drop map[int]bool) {
+	drop = make(map[int]bool)
+	first := make(map[string]int)
+	for i, t := range tasks {
+		key := s.taskKey(t.Name)
+		j, ok := first[key]
+		if !ok {
+			first[key] = i
+			continue
+		}
+
+		found(tasks[j], t)
+		switch policy {
+		case DuplicateTaskLastWins:
+			drop[j] = true
+			first[key] = i
+		case DuplicateTaskFirstWins:
+			drop[i] = true
+		}
+	}
+	return
+}
+
+// withoutIndices returns tasks without the ones at the given indices.
+func withoutIndices(tasks []Task, drop map[int]bool) (kept []Task) {
+	kept = make([]Task, 0, len(tasks)-len(drop))
+	for i, t := range tasks {
+		if !drop[i] {
+			kept = append(kept, t)
+		}
+	}
+	return
+}
@@ -0,0 +1,90 @@
+package config
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestResolveDuplicates(t *testing.T) {
+	tasks := []Task{
+		{Name: "backup", Source: "a.json"},
+		{Name: "upload", Source: "a.json"},
+		{Name: "Backup", Source: "b.yaml"},
+		{Name: "backup", Source: "c.toml"},
+	}
+	tests := []struct {
+		name          string
+		policy        string
+		caseSensitive bool
+		want          []string
+		err           error
+	}{
+		{
+			name: "default policy keeps every task",
+			want: []string{"backup a.json", "upload a.json", "Backup b.yaml", "backup c.toml"},
+		},
+		{
+			name:   "warn keeps every task",
+			policy: DuplicateTaskWarn,
+			want:   []string{"backup a.json", "upload a.json", "Backup b.yaml", "backup c.toml"},
+		},
+		{
+			name:   "policy is case-insensitive",
+			policy: "First-Wins",
+			want:   []string{"backup a.json", "upload a.json"},
+		},
+		{
+			name:   "first wins",
+			policy: DuplicateTaskFirstWins,
+			want:   []string{"backup a.json", "upload a.json"},
+		},
+		{
+			name:   "last wins",
+			policy: DuplicateTaskLastWins,
+			want:   []string{"upload a.json", "backup c.toml"},
+		},
+		{
+			name:          "case-sensitive names",
+			policy:        DuplicateTaskLastWins,
+			caseSensitive: true,
+			want:          []string{"upload a.json", "Backup b.yaml", "backup c.toml"},
+		},
+		{
+			name:   "error",
+			policy: DuplicateTaskError,
+			err:    ErrDuplicateTask,
+		},
+		{
+			name:   "unknown policy",
+			policy: "random",
+			err:    ErrUnknownPolicy,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Config{
+				GeneralSettings: GeneralSettings{DuplicateTaskPolicy: tt.policy, CaseSensitiveJobNames: tt.caseSensitive},
+				Tasks:           append([]Task(nil), tasks...),
+			}
+			err := c.resolveDuplicates()
+			if tt.err != nil {
+				if !errors.Is(err, tt.err) {
+					t.Fatalf("error = %v, want %v", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			var got []string
+			for _, task := range c.Tasks {
+				got = append(got, task.Name+" "+task.Source)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("tasks = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,198 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// ErrInvalidExtends is returned if the base task of Task.Extends can not be resolved.
+var ErrInvalidExtends = errors.New("invalid Extends")
+
+// notInherited contains the fields of a base task which are never inherited.
+var notInherited = []string{"Name", "Extends", "Abstract"}
+
+// The cycleError type is returned if tasks extend each other.
+type cycleError struct {
+	chain []string
+}
+
+func newCycleError(tasks []Task, chain []int) *cycleError {
+	e := &cycleError{}
+	for _, i := range chain {
+		e.chain = append(e.chain, tasks[i].Name)
+	}
+	return e
+}
+
+func (e *cycleError) Error() string {
+	return fmt.Sprintf("%v: tasks extend each other (%s)", ErrInvalidExtends, strings.Join(e.chain, " -> "))
+}
+
+func (e *cycleError) Unwrap() error {
+	return ErrInvalidExtends
+}
+
+// resolveExtends merges every task with the tasks it extends.
+// The returned tasks are in the same order as tasks, errs contains the error of each task which could not be resolved.
+// Abstract tasks are resolved as well and need to be removed afterwards.
+func resolveExtends(tasks []Task, s GeneralSettings) (resolved []Task, errs map[int]error) {
+	resolved = make([]Task, len(tasks))
+	errs = make(map[int]error)
+	byName := make(map[string][]int)
+	for i, t := range tasks {
+		key := s.taskKey(t.Name)
+		byName[key] = append(byName[key], i)
+	}
+
+	done := make([]bool, len(tasks))
+	stack := make([]int, 0)
+	var resolve func(i int) error
+	resolve = func(i int) (err error) {
+		if done[i] {
+			return errs[i]
+		}
+		for j, si := range stack {
+			if si == i {
+				return newCycleError(tasks, append(append([]int{}, stack[j:]...), i))
+			}
+		}
+		defer func() {
+			done[i] = true
+			if err != nil {
+				errs[i] = err
+			}
+		}()
+
+		t := tasks[i]
+		if t.Extends == "" {
+			resolved[i] = t
+			return
+		}
+
+		bases := byName[s.taskKey(t.Extends)]
+		switch {
+		case len(bases) == 0:
+			return fmt.Errorf("%w: base task \"%s\" of task \"%s\" does not exist", ErrInvalidExtends, t.Extends, t.Name)
+		case len(bases) > 1:
+			return fmt.Errorf("%w: base task \"%s\" of task \"%s\" is defined %d times", ErrInvalidExtends, t.Extends, t.Name, len(bases))
+		}
+
+		stack = append(stack, i)
+		err = resolve(bases[0])
+		stack = stack[:len(stack)-1]
+		var cycleErr *cycleError
+		if errors.As(err, &cycleErr) {
+			return
+		}
+		if err != nil {
+			return fmt.Errorf("%w: base task \"%s\" of task \"%s\" is invalid", ErrInvalidExtends, t.Extends, t.Name)
+		}
+
+		resolved[i], err = mergeTask(resolved[bases[0]], t)
+		if err != nil {
+			return fmt.Errorf("%w: unable to merge task \"%s\" with \"%s\": %v", ErrInvalidExtends, t.Name, t.Extends, err)
+		}
+		return
+	}
+
+	for i := range tasks {
+		_ = resolve(i)
+	}
+	return
+}
+
+// mergeTask merges the values set inside child with base.
+// Maps (e.g. Dynamic) and structs (e.g. Compression) are merged key by key, lists (e.g. Arguments, PreOperations)
+// and every other value are replaced if set inside child.
+func mergeTask(base, child Task) (merged Task, err error) {
+	raw := copyRaw(base.raw)
+	for _, k := range notInherited {
+		delete(raw, k)
+	}
+	raw = mergeRaw(raw, child.raw, reflect.TypeOf(Task{}))
+
+	b, err := json.Marshal(raw)
+	if err != nil {
+		return
+	}
+	err = json.Unmarshal(b, &merged)
+	if err != nil {
+		return
+	}
+	merged.Source = child.Source
+	merged.Args = child.Args
+	merged.raw = raw
+	merged.defaults = child.defaults
+	return
+}
+
+// mergeRaw merges child into base, both decoded as t.
+func mergeRaw(base, child map[string]any, t reflect.Type) map[string]any {
+	if base == nil {
+		base = make(map[string]any)
+	}
+	for k, v := range child {
+		field, ok := t.FieldByName(k)
+		bm, baseIsMap := base[k].(map[string]any)
+		cm, childIsMap := v.(map[string]any)
+		if !ok || !baseIsMap || !childIsMap {
+			base[k] = v
+			continue
+		}
+
+		switch field.Type.Kind() {
+		case reflect.Struct:
+			base[k] = mergeRaw(copyRaw(bm), cm, field.Type)
+		case reflect.Map:
+			m := copyRaw(bm)
+			for mk, mv := range cm {
+				m[mk] = mv
+			}
+			base[k] = m
+		default:
+			base[k] = v
+		}
+	}
+	return base
+}
+
+// copyRaw returns a shallow copy of m.
+func copyRaw(m map[string]any) (c map[string]any) {
+	c = make(map[string]any, len(m))
+	for k, v := range m {
+		c[k] = v
+	}
+	return
+}
+
+// normalizeRaw renames the keys of m to the field names of t, nested structs included.
+// Keys of case-insensitive formats (e.g. json) need to be normalized before merging.
+func normalizeRaw(m map[string]any, t reflect.Type, f *Format) map[string]any {
+	normalized := make(map[string]any, len(m))
+	for k, v := range m {
+		sf, _, ok := lookupField(t, k, f)
+		if !ok {
+			continue
+		}
+		nested, isMap := v.(map[string]any)
+		if isMap && sf.Type.Kind() == reflect.Struct {
+			v = normalizeRaw(nested, sf.Type, f)
+		}
+		normalized[sf.Name] = v
+	}
+	return normalized
+}
+
+// removeAbstract returns tasks without the abstract ones.
+func removeAbstract(tasks []Task) (concrete []Task) {
+	concrete = make([]Task, 0, len(tasks))
+	for _, t := range tasks {
+		if !t.Abstract {
+			concrete = append(concrete, t)
+		}
+	}
+	return
+}
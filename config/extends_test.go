@@ -0,0 +1,200 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// loadTestConfig decodes content like a config file with the given name, the format is chosen by its extension.
+func loadTestConfig(t *testing.T, name, content string) (c Config) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	err := os.WriteFile(path, []byte(content), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = c.LoadInto(path)
+	if err != nil {
+		t.Fatalf("unable to load %s: %v", name, err)
+	}
+	return
+}
+
+func TestResolveExtends(t *testing.T) {
+	c := loadTestConfig(t, "config.yaml", `
+Tasks:
+  - Name: Backup
+    Abstract: true
+    Command: restic
+    Tags: [backup]
+    Arguments: [backup, "%Dynamic.Source%"]
+    StopIfUnsuccessful: true
+    Dynamic:
+      Source: /srv
+      Repo: /mnt/repo
+    Compression:
+      PathToCompress: /srv
+      InMemoryCompressionLimit: 1GB
+  - Name: BackupPhotos
+    Extends: Backup
+    Dynamic:
+      Source: /srv/photos
+    Compression:
+      OverwriteCompressed: true
+  - Name: BackupMusic
+    Extends: backupphotos
+    Tags: []
+    Arguments: [backup, --verbose]
+    StopIfUnsuccessful: false
+`)
+	resolved, errs := resolveExtends(c.Tasks, GeneralSettings{})
+	if len(errs) > 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+
+	photos := resolved[1]
+	tests := []struct {
+		name string
+		got  any
+		want any
+	}{
+		{name: "scalar inherited", got: photos.Command, want: "restic"},
+		{name: "bool inherited", got: photos.StopIfUnsuccessful, want: true},
+		{name: "list inherited", got: photos.Tags, want: []string{"backup"}},
+		{name: "map merged key by key", got: photos.Dynamic, want: map[string]any{"Source": "/srv/photos", "Repo": "/mnt/repo"}},
+		{name: "struct merged field by field", got: photos.Compression, want: CompressionOptions{PathToCompress: "/srv", InMemoryCompressionLimit: "1GB", OverwriteCompressed: true}},
+		{name: "name not inherited", got: photos.Name, want: "BackupPhotos"},
+		{name: "abstract not inherited", got: photos.Abstract, want: false},
+		{name: "source kept", got: photos.Source, want: c.Tasks[1].Source},
+		{name: "chain inherits from all bases", got: resolved[2].Dynamic["Repo"], want: "/mnt/repo"},
+		{name: "empty list removes the inherited one", got: resolved[2].Tags, want: []string{}},
+		{name: "list replaced", got: resolved[2].Arguments, want: []string{"backup", "--verbose"}},
+		{name: "false replaces true", got: resolved[2].StopIfUnsuccessful, want: false},
+		{name: "extends kept", got: resolved[2].Extends, want: "backupphotos"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if !reflect.DeepEqual(tt.got, tt.want) {
+				t.Errorf("got %#v, want %#v", tt.got, tt.want)
+			}
+		})
+	}
+
+	names := make([]string, 0)
+	for _, task := range removeAbstract(resolved) {
+		names = append(names, task.Name)
+	}
+	if !reflect.DeepEqual(names, []string{"BackupPhotos", "BackupMusic"}) {
+		t.Errorf("removeAbstract() = %v, want the concrete tasks", names)
+	}
+}
+
+func TestResolveExtendsErrors(t *testing.T) {
+	tests := []struct {
+		name          string
+		config        string
+		caseSensitive bool
+		failed        []int
+		msg           string
+	}{
+		{
+			name: "unknown base",
+			config: `
+Tasks:
+  - Name: a
+    Extends: missing
+`,
+			failed: []int{0},
+			msg:    `base task "missing" of task "a" does not exist`,
+		},
+		{
+			name: "case-sensitive names",
+			config: `
+Tasks:
+  - Name: Base
+  - Name: a
+    Extends: base
+`,
+			caseSensitive: true,
+			failed:        []int{1},
+			msg:           `base task "base" of task "a" does not exist`,
+		},
+		{
+			name: "ambiguous base",
+			config: `
+Tasks:
+  - Name: base
+  - Name: base
+  - Name: a
+    Extends: base
+`,
+			failed: []int{2},
+			msg:    `base task "base" of task "a" is defined 2 times`,
+		},
+		{
+			name: "invalid base",
+			config: `
+Tasks:
+  - Name: base
+    Extends: missing
+  - Name: a
+    Extends: base
+`,
+			failed: []int{0, 1},
+			msg:    `base task "base" of task "a" is invalid`,
+		},
+		{
+			name: "cycle",
+			config: `
+Tasks:
+  - Name: a
+    Extends: b
+  - Name: b
+    Extends: a
+  - Name: c
+    Extends: a
+`,
+			failed: []int{0, 1, 2},
+			msg:    "tasks extend each other (a -> b -> a)",
+		},
+		{
+			name: "self",
+			config: `
+Tasks:
+  - Name: a
+    Extends: a
+`,
+			failed: []int{0},
+			msg:    "tasks extend each other (a -> a)",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := loadTestConfig(t, "config.yaml", tt.config)
+			_, errs := resolveExtends(c.Tasks, GeneralSettings{CaseSensitiveJobNames: tt.caseSensitive})
+
+			var failed []int
+			found := false
+			for i := range c.Tasks {
+				if errs[i] == nil {
+					continue
+				}
+				failed = append(failed, i)
+				if !errors.Is(errs[i], ErrInvalidExtends) {
+					t.Errorf("error of task #%d = %v, want %v", i, errs[i], ErrInvalidExtends)
+				}
+				found = found || strings.Contains(errs[i].Error(), tt.msg)
+			}
+			if !reflect.DeepEqual(failed, tt.failed) {
+				t.Errorf("failed tasks = %v, want %v", failed, tt.failed)
+			}
+			if !found {
+				t.Errorf("no error contains %q: %v", tt.msg, errs)
+			}
+		})
+	}
+}
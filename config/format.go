@@ -0,0 +1,262 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	FormatJson = "json"
+	FormatYaml = "yaml"
+	FormatToml = "toml"
+)
+
+var (
+	// ErrUnknownFormat is returned if no format has been registered for a name or file extension.
+	ErrUnknownFormat = errors.New("unknown config format")
+
+	// ErrFormatExists is returned if a format with the same name or extension has already been registered.
+	ErrFormatExists = errors.New("config format already registered")
+)
+
+var (
+	formatMux sync.RWMutex
+	formats   = []*Format{
+		{
+			Name:       FormatJson,
+			Extensions: []string{".json"},
+			TagName:    "json",
+			Marshal: func(v any) ([]byte, error) {
+				buf := &bytes.Buffer{}
+				enc := json.NewEncoder(buf)
+				enc.SetEscapeHTML(false)
+				enc.SetIndent("", "\t")
+				err := enc.Encode(v)
+				return buf.Bytes(), err
+			},
+			Unmarshal: json.Unmarshal,
+			unmarshalDoc: func(b []byte, v any) error {
+				dec := json.NewDecoder(bytes.NewReader(b))
+				dec.UseNumber()
+				err := dec.Decode(v)
+				if err == nil && dec.More() {
+					err = errors.New("invalid character after top-level value")
+				}
+				return err
+			},
+			parseNode: func(b []byte) (*node, error) {
+				dec := json.NewDecoder(bytes.NewReader(b))
+				dec.UseNumber()
+				return parseJsonNode(dec, b)
+			},
+		},
+		{
+			Name:          FormatYaml,
+			Extensions:    []string{".yaml", ".yml"},
+			TagName:       "yaml",
+			CaseSensitive: true,
+			Marshal:       yaml.Marshal,
+			Unmarshal:     yaml.Unmarshal,
+			parseNode:     parseYamlNode,
+		},
+		{
+			Name:       FormatToml,
+			Extensions: []string{".toml"},
+			TagName:    "toml",
+			Marshal:    toml.Marshal,
+			Unmarshal:  toml.Unmarshal,
+			addLines:   addTomlLines,
+		},
+	}
+)
+
+// The Format type describes a config file format which can be loaded and written.
+type Format struct {
+	// Name is the unique name of the format, e.g. "yaml".
+	Name string
+
+	// Extensions contains the file extensions of the format including the dot, e.g. ".yaml" and ".yml".
+	// The first extension is used to create new files.
+	Extensions []string
+
+	// TagName is the struct tag used by Marshal and Unmarshal, "json" if empty.
+	TagName string
+
+	// CaseSensitive defines whether the keys of a file need to match the case of the struct tags.
+	CaseSensitive bool
+
+	// Marshal encodes v.
+	Marshal func(v any) ([]byte, error)
+
+	// Unmarshal decodes b into v.
+	Unmarshal func(b []byte, v any) error
+
+	// unmarshalDoc decodes a file into a generic value without losing the precision of its numbers.
+	// If nil, Unmarshal is used.
+	unmarshalDoc func(b []byte, v any) error
+
+	// parseNode parses a file into a tree containing the line of each value, used for validation.
+	// If nil, the file is decoded without line information.
+	parseNode func(b []byte) (*node, error)
+
+	// addLines adds the lines to the tree of a file decoded without parseNode.
+	addLines func(b []byte, n *node)
+}
+
+// RegisterFormat registers an additional config format.
+// Files with one of its extensions are loaded by LoadAll and new configs can be created in this format.
+func RegisterFormat(f Format) (err error) {
+	if f.Name == "" || len(f.Extensions) == 0 || f.Marshal == nil || f.Unmarshal == nil {
+		return errors.New("a config format requires a name, at least one extension, Marshal and Unmarshal")
+	}
+	if f.TagName == "" {
+		f.TagName = "json"
+	}
+	exts := make([]string, len(f.Extensions))
+	for i, ext := range f.Extensions {
+		ext = strings.ToLower(ext)
+		if !strings.HasPrefix(ext, ".") {
+			ext = "." + ext
+		}
+		exts[i] = ext
+	}
+	f.Extensions = exts
+
+	formatMux.Lock()
+	defer formatMux.Unlock()
+	for _, existing := range formats {
+		if strings.EqualFold(existing.Name, f.Name) {
+			return fmt.Errorf("%w: %s", ErrFormatExists, f.Name)
+		}
+		for _, ext := range f.Extensions {
+			if existing.hasExtension(ext) {
+				return fmt.Errorf("%w: %s", ErrFormatExists, ext)
+			}
+		}
+	}
+	formats = append(formats, &f)
+	return
+}
+
+// FormatNames returns the names of all registered formats in the order they have been registered.
+func FormatNames() []string {
+	formatMux.RLock()
+	defer formatMux.RUnlock()
+	return formatNames()
+}
+
+// formatNames returns the names of all registered formats, the caller needs to hold formatMux.
+func formatNames() (names []string) {
+	for _, f := range formats {
+		names = append(names, f.Name)
+	}
+	return
+}
+
+// formatByName returns the registered format with the given name.
+func formatByName(name string) (f *Format, err error) {
+	formatMux.RLock()
+	defer formatMux.RUnlock()
+	for _, f = range formats {
+		if strings.EqualFold(f.Name, name) {
+			return
+		}
+	}
+	return nil, fmt.Errorf("%w: %s, expected one of %s", ErrUnknownFormat, name, strings.Join(formatNames(), ", "))
+}
+
+// formatOf returns the registered format of the given file.
+func formatOf(path string) (f *Format, err error) {
+	ext := strings.ToLower(filepath.Ext(path))
+	formatMux.RLock()
+	defer formatMux.RUnlock()
+	for _, f = range formats {
+		if f.hasExtension(ext) {
+			return
+		}
+	}
+	return nil, fmt.Errorf("%w: %s", ErrUnknownFormat, filepath.Base(path))
+}
+
+// FormatOf returns the name of the registered format of the given file.
+func FormatOf(path string) (name string, err error) {
+	f, err := formatOf(path)
+	if err != nil {
+		return
+	}
+	return f.Name, nil
+}
+
+// isConfigFile returns whether the given path has the extension of a registered format.
+func isConfigFile(path string) bool {
+	_, err := formatOf(path)
+	return err == nil
+}
+
+// configFileNames returns the possible names of the main config, one for each registered extension.
+func configFileNames() (names []string) {
+	formatMux.RLock()
+	defer formatMux.RUnlock()
+	for _, f := range formats {
+		for _, ext := range f.Extensions {
+			names = append(names, fileBaseName+ext)
+		}
+	}
+	return
+}
+
+// hasExtension returns whether ext (in lower case) belongs to f.
+func (f *Format) hasExtension(ext string) bool {
+	for _, e := range f.Extensions {
+		if e == ext {
+			return true
+		}
+	}
+	return false
+}
+
+// fileName returns the name of the main config in this format.
+func (f *Format) fileName() string {
+	return fileBaseName + f.Extensions[0]
+}
+
+// decodeDoc decodes b into a generic document, numbers are kept as they are written inside b.
+func (f *Format) decodeDoc(b []byte) (doc map[string]any, err error) {
+	if f.unmarshalDoc != nil {
+		err = f.unmarshalDoc(b, &doc)
+	} else {
+		err = f.Unmarshal(b, &doc)
+	}
+	if err == nil && doc == nil {
+		doc = make(map[string]any)
+	}
+	return
+}
+
+// parse parses b into a node tree.
+// Formats without a dedicated parser are decoded generically,
+// the nodes only contain the lines added by addLines in this case.
+func (f *Format) parse(b []byte) (n *node, err error) {
+	if f.parseNode != nil {
+		return f.parseNode(b)
+	}
+
+	var v any
+	err = f.Unmarshal(b, &v)
+	if err != nil {
+		return
+	}
+	n = fromValue(v)
+	if f.addLines != nil {
+		f.addLines(b, n)
+	}
+	return
+}
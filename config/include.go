@@ -0,0 +1,76 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// The includes type contains the files the main config includes.
+type includes struct {
+	// paths contains the included files in the order they are loaded.
+	paths []string
+
+	// unmatched contains every pattern which did not match any file.
+	unmatched []string
+
+	// disableScan is true if only the main config and the included files are loaded.
+	disableScan bool
+}
+
+// readIncludes resolves the Include list of the given main config.
+// Relative patterns are resolved from the directory of the main config, the matches of each pattern are sorted lexically.
+// Decoding errors are ignored since they are reported when the file is loaded.
+func readIncludes(main string) (inc includes, err error) {
+	b, err := os.ReadFile(main)
+	if err != nil {
+		return
+	}
+
+	var conf struct {
+		Include         []string `json:"Include" yaml:"Include" toml:"Include"`
+		GeneralSettings struct {
+			DisableConfigDirScan bool `json:"DisableConfigDirScan" yaml:"DisableConfigDirScan" toml:"DisableConfigDirScan"`
+		} `json:"GeneralSettings" yaml:"GeneralSettings" toml:"GeneralSettings"`
+	}
+	f, err := formatOf(main)
+	if err != nil {
+		return
+	}
+	_ = f.Unmarshal(b, &conf)
+	inc.disableScan = conf.GeneralSettings.DisableConfigDirScan
+
+	dir := filepath.Dir(main)
+	for _, pattern := range conf.Include {
+		path := pattern
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(dir, path)
+		}
+
+		// Plain paths need to exist, loading them reports the error otherwise.
+		if !strings.ContainsAny(path, "*?[") {
+			inc.paths = append(inc.paths, path)
+			continue
+		}
+
+		var matches []string
+		matches, err = filepath.Glob(path)
+		if err != nil {
+			return inc, fmt.Errorf("invalid Include pattern %q: %v", pattern, err)
+		}
+		matched := false
+		for _, m := range matches {
+			stat, sErr := os.Stat(m)
+			if sErr != nil || stat.IsDir() || !isConfigFile(m) {
+				continue
+			}
+			inc.paths = append(inc.paths, m)
+			matched = true
+		}
+		if !matched {
+			inc.unmatched = append(inc.unmatched, pattern)
+		}
+	}
+	return
+}
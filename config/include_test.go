@@ -0,0 +1,88 @@
+package config
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestIncludeOrder(t *testing.T) {
+	tests := []struct {
+		name      string
+		include   string
+		disable   bool
+		want      []string
+		unmatched []string
+	}{
+		{
+			name: "without include the directory is scanned lexically",
+			want: []string{"config.yaml", "a.yaml", "b.json", "conf.d/10-z.yaml", "conf.d/2-a.yaml", "conf.d/notes.txt.yaml", "z.toml"},
+		},
+		{
+			name:    "includes are loaded before the scanned files",
+			include: `["z.toml", "a.yaml"]`,
+			want:    []string{"config.yaml", "z.toml", "a.yaml", "b.json", "conf.d/10-z.yaml", "conf.d/2-a.yaml", "conf.d/notes.txt.yaml"},
+		},
+		{
+			name:    "glob matches are sorted lexically",
+			include: `["conf.d/*", "b.json"]`,
+			disable: true,
+			want:    []string{"config.yaml", "conf.d/10-z.yaml", "conf.d/2-a.yaml", "conf.d/notes.txt.yaml", "b.json"},
+		},
+		{
+			name:    "files are only loaded once",
+			include: `["b.json", "*.json", "b.json"]`,
+			disable: true,
+			want:    []string{"config.yaml", "b.json"},
+		},
+		{
+			name:      "unmatched patterns",
+			include:   `["missing/*.yaml", "a.yaml"]`,
+			disable:   true,
+			want:      []string{"config.yaml", "a.yaml"},
+			unmatched: []string{"missing/*.yaml"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			main := "Version: 1\nGeneralSettings:\n  GlobalCommand: echo\n"
+			if tt.disable {
+				main += "  DisableConfigDirScan: true\n"
+			}
+			if tt.include != "" {
+				main += "Include: " + tt.include + "\n"
+			}
+			dir := setupConfigDir(t, map[string]string{
+				"config.yaml":           main,
+				"a.yaml":                "Version: 1\n",
+				"b.json":                `{"Version": 1}`,
+				"z.toml":                "Version = 1\n",
+				"conf.d/10-z.yaml":      "Version: 1\n",
+				"conf.d/2-a.yaml":       "Version: 1\n",
+				"conf.d/notes.txt.yaml": "Version: 1\n",
+				"conf.d/notes.txt":      "not a config",
+			})
+
+			list, err := files()
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			got := make([]string, len(list))
+			for i, f := range list {
+				got[i], _ = filepath.Rel(dir, f.path)
+				got[i] = filepath.ToSlash(got[i])
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("files() = %v, want %v", got, tt.want)
+			}
+
+			inc, err := readIncludes(filepath.Join(dir, "config.yaml"))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(inc.unmatched, tt.unmatched) {
+				t.Errorf("unmatched = %v, want %v", inc.unmatched, tt.unmatched)
+			}
+		})
+	}
+}
@@ -0,0 +1,300 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// CurrentVersion is the version of the config structure, written to the Version key of new files.
+// Files without a Version key are treated as version 0.
+const CurrentVersion = 1
+
+// versionKey is the key of the config version inside a file.
+const versionKey = "Version"
+
+// ErrInvalidVersion is returned if the Version of a file is not a known version.
+var ErrInvalidVersion = errors.New("invalid Version")
+
+// The migration type upgrades a decoded config file from version to version+1.
+// Every change applied to doc needs to be described by a note, the notes are logged as warnings.
+// A migration which does not change the structure returns no notes, files only missing the new Version are loaded silently.
+// A migration must not set the Version itself.
+type migration struct {
+	version int
+	migrate func(doc map[string]any) (notes []string, err error)
+}
+
+// migrations contains a migration for every version before CurrentVersion, in ascending order.
+var migrations = []migration{
+	{
+		// Version 1 introduced the Version key, the structure itself is unchanged.
+		version: 0,
+		migrate: func(map[string]any) ([]string, error) {
+			return nil, nil
+		},
+	},
+}
+
+// The MigrationResult type describes the migration of a single file.
+type MigrationResult struct {
+	Path string
+
+	// Backup is the path of the copy of the original file, empty if the file has not been written.
+	Backup string
+
+	From, To int
+
+	// Notes describe the changes applied to the file.
+	Notes []string
+}
+
+// Migrated returns whether the file needed to be migrated.
+func (r MigrationResult) Migrated() bool {
+	return r.From != r.To
+}
+
+// MigrateFile upgrades the given file to CurrentVersion and rewrites it in its original format.
+// The original file is kept next to it with the suffix ".v<From>.bak", an existing backup is never overwritten.
+// If dryRun is set, the file is only checked.
+// Keys keep their order in json and yaml files, comments are not kept.
+func MigrateFile(path string, dryRun bool) (res MigrationResult, err error) {
+	res = MigrationResult{Path: path, To: CurrentVersion}
+	f, err := formatOf(path)
+	if err != nil {
+		return
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return
+	}
+
+	doc, err := f.decodeDoc(b)
+	if err != nil {
+		return
+	}
+	res.From, res.Notes, err = migrate(doc)
+	if err != nil || !res.Migrated() || dryRun {
+		return
+	}
+
+	var v any = doc
+	if f.parseNode != nil {
+		var root *node
+		root, err = f.parseNode(b)
+		if err != nil {
+			return
+		}
+		v = ordered(doc, root)
+	}
+	out, err := f.Marshal(v)
+	if err != nil {
+		return
+	}
+
+	stat, err := os.Stat(path)
+	if err != nil {
+		return
+	}
+	backup := fmt.Sprintf("%s.v%d.bak", path, res.From)
+	bf, err := os.OpenFile(backup, os.O_WRONLY|os.O_CREATE|os.O_EXCL, stat.Mode().Perm())
+	if err != nil {
+		return
+	}
+	_, err = bf.Write(b)
+	cErr := bf.Close()
+	if err == nil {
+		err = cErr
+	}
+	if err != nil {
+		return
+	}
+	res.Backup = backup
+
+	err = os.WriteFile(path, out, stat.Mode().Perm())
+	return
+}
+
+// Files returns every config file LoadAll loads in the order they are loaded.
+func Files() (paths []string, err error) {
+	list, err := files()
+	if err != nil {
+		return
+	}
+	for _, f := range list {
+		paths = append(paths, f.path)
+	}
+	return
+}
+
+// MainFile returns the path of the main config.
+func MainFile() (path string, err error) {
+	list, err := files()
+	if err != nil {
+		return
+	}
+	for _, f := range list {
+		if f.isMain {
+			return f.path, nil
+		}
+	}
+	return "", fmt.Errorf("%w: none of %s exists", ErrNotFound, strings.Join(configFileNames(), ", "))
+}
+
+// migrate upgrades doc to CurrentVersion.
+// It returns the version doc has been written for and the notes of all applied migrations.
+func migrate(doc map[string]any) (from int, notes []string, err error) {
+	from, err = docVersion(doc)
+	if err != nil {
+		return
+	}
+	if from > CurrentVersion {
+		return from, nil, fmt.Errorf("%w: the file has been written for version %d, this build supports up to %d", ErrInvalidVersion, from, CurrentVersion)
+	}
+	if from == CurrentVersion {
+		return
+	}
+
+	for _, m := range migrations {
+		if m.version < from {
+			continue
+		}
+		var n []string
+		n, err = m.migrate(doc)
+		if err != nil {
+			return from, notes, fmt.Errorf("unable to migrate from version %d to %d: %v", m.version, m.version+1, err)
+		}
+		notes = append(notes, n...)
+	}
+	for k := range doc {
+		if strings.EqualFold(k, versionKey) {
+			delete(doc, k)
+		}
+	}
+	doc[versionKey] = CurrentVersion
+	return
+}
+
+// docVersion returns the Version of doc, 0 if it is not set.
+func docVersion(doc map[string]any) (version int, err error) {
+	var v any
+	for k, val := range doc {
+		if strings.EqualFold(k, versionKey) {
+			v = val
+		}
+	}
+
+	switch val := v.(type) {
+	case nil:
+		return 0, nil
+	case int:
+		version = val
+	case int64:
+		version = int(val)
+	case uint64:
+		version = int(val)
+	case float64:
+		if val != math.Trunc(val) {
+			return 0, fmt.Errorf("%w: %v is not a whole number", ErrInvalidVersion, val)
+		}
+		version = int(val)
+	case json.Number:
+		version, err = strconv.Atoi(val.String())
+	default:
+		err = ErrInvalidVersion
+	}
+	if err != nil || version < 0 {
+		return 0, fmt.Errorf("%w: %v is not a version number", ErrInvalidVersion, v)
+	}
+	return
+}
+
+// The orderedMap type is a map which is encoded in the given order of its keys.
+type orderedMap struct {
+	keys   []string
+	values map[string]any
+}
+
+// ordered converts every map of v into an orderedMap, the keys are ordered as inside n.
+// Keys which do not exist inside n are sorted and placed in front of the others.
+func ordered(v any, n *node) any {
+	switch val := v.(type) {
+	case map[string]any:
+		pos := make(map[string]int)
+		if n != nil && n.kind == nodeObject {
+			for i, f := range n.fields {
+				pos[f.key] = i + 1
+			}
+		}
+
+		m := orderedMap{values: make(map[string]any, len(val))}
+		for k, item := range val {
+			m.keys = append(m.keys, k)
+			m.values[k] = ordered(item, n.get(k))
+		}
+		sort.Slice(m.keys, func(i, j int) bool {
+			a, b := pos[m.keys[i]], pos[m.keys[j]]
+			if a != b {
+				return a < b
+			}
+			return m.keys[i] < m.keys[j]
+		})
+		return m
+	case []any:
+		items := make([]any, len(val))
+		for i, item := range val {
+			items[i] = ordered(item, n.item(i))
+		}
+		return items
+	}
+	return v
+}
+
+func (m orderedMap) MarshalJSON() ([]byte, error) {
+	buf := &bytes.Buffer{}
+	enc := json.NewEncoder(buf)
+	enc.SetEscapeHTML(false)
+	buf.WriteByte('{')
+	for i, k := range m.keys {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		err := enc.Encode(k)
+		if err != nil {
+			return nil, err
+		}
+		buf.WriteByte(':')
+		err = enc.Encode(m.values[k])
+		if err != nil {
+			return nil, err
+		}
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+func (m orderedMap) MarshalYAML() (any, error) {
+	n := &yaml.Node{Kind: yaml.MappingNode}
+	for _, k := range m.keys {
+		key := &yaml.Node{}
+		err := key.Encode(k)
+		if err != nil {
+			return nil, err
+		}
+		value := &yaml.Node{}
+		err = value.Encode(m.values[k])
+		if err != nil {
+			return nil, err
+		}
+		n.Content = append(n.Content, key, value)
+	}
+	return n, nil
+}
@@ -0,0 +1,174 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestMigrate(t *testing.T) {
+	tests := []struct {
+		name     string
+		doc      map[string]any
+		from     int
+		migrated bool
+		err      error
+	}{
+		{name: "no version", doc: map[string]any{"Tasks": []any{}}, from: 0, migrated: true},
+		{name: "version 0", doc: map[string]any{"Version": 0}, from: 0, migrated: true},
+		{name: "lower case key", doc: map[string]any{"version": int64(0)}, from: 0, migrated: true},
+		{name: "current version", doc: map[string]any{"Version": CurrentVersion}, from: CurrentVersion},
+		{name: "whole float", doc: map[string]any{"Version": float64(CurrentVersion)}, from: CurrentVersion},
+		{name: "json number", doc: map[string]any{"Version": json.Number("0")}, from: 0, migrated: true},
+		{name: "fraction", doc: map[string]any{"Version": 0.5}, err: ErrInvalidVersion},
+		{name: "negative", doc: map[string]any{"Version": -1}, err: ErrInvalidVersion},
+		{name: "string", doc: map[string]any{"Version": "1"}, err: ErrInvalidVersion},
+		{name: "newer version", doc: map[string]any{"Version": CurrentVersion + 1}, from: CurrentVersion + 1, err: ErrInvalidVersion},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			from, notes, err := migrate(tt.doc)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("error = %v, want %v", err, tt.err)
+			}
+			if err != nil {
+				return
+			}
+			if from != tt.from {
+				t.Errorf("from = %d, want %d", from, tt.from)
+			}
+			// No migration changes the structure yet, the missing Version alone is not worth a note.
+			if len(notes) > 0 {
+				t.Errorf("notes = %q, want none", notes)
+			}
+			if tt.doc[versionKey] != CurrentVersion && tt.migrated {
+				t.Errorf("Version = %v, want %d", tt.doc[versionKey], CurrentVersion)
+			}
+			if _, ok := tt.doc["version"]; ok && tt.migrated {
+				t.Error("the lower case version key has not been replaced")
+			}
+		})
+	}
+}
+
+func TestMigrateFile(t *testing.T) {
+	const content = `# comment
+GeneralSettings:
+  GlobalCommand: echo
+Tasks:
+  - Name: b
+    Command: ls
+  - Name: a
+`
+	tests := []struct {
+		name    string
+		content string
+		dryRun  bool
+		backup  bool
+		written bool
+	}{
+		{name: "migrated", content: content, backup: true, written: true},
+		{name: "dry run", content: content, dryRun: true},
+		{name: "up to date", content: "Version: 1\n" + content},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "config.yaml")
+			err := os.WriteFile(path, []byte(tt.content), 0600)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			res, err := MigrateFile(path, tt.dryRun)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if (res.Backup != "") != tt.backup {
+				t.Errorf("Backup = %q, backup expected: %t", res.Backup, tt.backup)
+			}
+			b, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if (string(b) != tt.content) != tt.written {
+				t.Errorf("file written = %t, want %t:\n%s", string(b) != tt.content, tt.written, b)
+			}
+			if !tt.written {
+				return
+			}
+
+			backup, err := os.ReadFile(res.Backup)
+			if err != nil || string(backup) != tt.content {
+				t.Errorf("backup = %q (%v), want the original content", backup, err)
+			}
+			// The keys keep their order, the new Version key is placed in front of them.
+			order := []string{"Version: 1", "GeneralSettings:", "Tasks:", "Name: b", "Name: a"}
+			last := -1
+			for _, key := range order {
+				i := strings.Index(string(b), key)
+				if i <= last {
+					t.Errorf("%q is not in order:\n%s", key, b)
+				}
+				last = i
+			}
+
+			// An existing backup is never overwritten.
+			err = os.WriteFile(path, []byte(tt.content), 0600)
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, err = MigrateFile(path, false)
+			if !errors.Is(err, os.ErrExist) {
+				t.Errorf("error = %v, want %v", err, os.ErrExist)
+			}
+		})
+	}
+}
+
+func TestMigrateFileNumbers(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	err := os.WriteFile(path, []byte(`{"GlobalDynamic": {"Id": 12345678901234567890, "Ratio": 0.1}}`), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = MigrateFile(path, false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, number := range []string{"12345678901234567890", "0.1"} {
+		if !strings.Contains(string(b), number) {
+			t.Errorf("%s has not been kept:\n%s", number, b)
+		}
+	}
+}
+
+func TestLoadWithoutVersion(t *testing.T) {
+	buf := &strings.Builder{}
+	log.SetOutput(buf)
+	defer log.SetOutput(os.Stderr)
+
+	files := map[string]string{
+		"config.yaml":   "GeneralSettings:\n  GlobalCommand: echo\nTasks:\n  - Name: a\n    Command: ls\n",
+		"fragment.yaml": "Tasks:\n  - Name: b\n    Command: ls\n",
+	}
+	conf := reloadFiles(t, files)
+	if len(conf.Tasks()) != 2 {
+		t.Errorf("%d tasks loaded, want 2", len(conf.Tasks()))
+	}
+	if buf.Len() > 0 {
+		t.Errorf("unexpected log output:\n%s", buf)
+	}
+
+	diags := validateFiles(t, files)
+	for _, d := range diags {
+		t.Errorf("unexpected diagnostic: %s", d)
+	}
+}
@@ -0,0 +1,258 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	nodeScalar nodeKind = iota
+	nodeObject
+	nodeArray
+)
+
+var yamlLineReg = regexp.MustCompile(`line (\d+): (.*)`)
+
+type nodeKind int
+
+// The node type is a decoded json or yaml value which remembers its line inside the file.
+type node struct {
+	kind   nodeKind
+	line   int
+	fields []nodeField
+	items  []*node
+	value  any
+}
+
+// The nodeField type is a single key of an object node.
+type nodeField struct {
+	key   string
+	line  int
+	value *node
+}
+
+// get returns the value of the given key or nil if it does not exist.
+// Keys are compared case-insensitively just like encoding/json does.
+func (n *node) get(key string) *node {
+	f := n.field(key)
+	if f == nil {
+		return nil
+	}
+	return f.value
+}
+
+// field returns the field of the given key or nil if it does not exist.
+func (n *node) field(key string) *nodeField {
+	if n == nil || n.kind != nodeObject {
+		return nil
+	}
+	for i := range n.fields {
+		if strings.EqualFold(n.fields[i].key, key) {
+			return &n.fields[i]
+		}
+	}
+	return nil
+}
+
+// item returns the i-th item of an array node or nil if it does not exist.
+func (n *node) item(i int) *node {
+	if n == nil || n.kind != nodeArray || i < 0 || i >= len(n.items) {
+		return nil
+	}
+	return n.items[i]
+}
+
+// lineOf returns the line of the given key.
+// If the key does not exist, the line of n itself is returned.
+func (n *node) lineOf(key string) int {
+	f := n.field(key)
+	if f != nil {
+		return f.line
+	}
+	if n == nil {
+		return 0
+	}
+	return n.line
+}
+
+// parseYamlNode parses the yaml document b into a node tree.
+// A nil node is returned for an empty document.
+func parseYamlNode(b []byte) (n *node, err error) {
+	var doc yaml.Node
+	err = yaml.Unmarshal(b, &doc)
+	if err != nil || len(doc.Content) == 0 {
+		return
+	}
+	return fromYamlNode(doc.Content[0]), nil
+}
+
+// fromValue converts a generically decoded value, the nodes do not contain any line.
+func fromValue(v any) (n *node) {
+	n = &node{}
+	switch val := v.(type) {
+	case map[string]any:
+		n.kind = nodeObject
+		keys := make([]string, 0, len(val))
+		for k := range val {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			n.fields = append(n.fields, nodeField{key: k, value: fromValue(val[k])})
+		}
+	case []any:
+		n.kind = nodeArray
+		for _, item := range val {
+			n.items = append(n.items, fromValue(item))
+		}
+	case []map[string]any:
+		n.kind = nodeArray
+		for _, item := range val {
+			n.items = append(n.items, fromValue(item))
+		}
+	default:
+		n.value = v
+	}
+	return
+}
+
+// fromYamlNode converts the given yaml node.
+func fromYamlNode(y *yaml.Node) (n *node) {
+	for y.Kind == yaml.AliasNode && y.Alias != nil {
+		y = y.Alias
+	}
+
+	n = &node{line: y.Line}
+	switch y.Kind {
+	case yaml.MappingNode:
+		n.kind = nodeObject
+		for i := 0; i+1 < len(y.Content); i += 2 {
+			n.fields = append(n.fields, nodeField{
+				key:   y.Content[i].Value,
+				line:  y.Content[i].Line,
+				value: fromYamlNode(y.Content[i+1]),
+			})
+		}
+	case yaml.SequenceNode:
+		n.kind = nodeArray
+		for _, c := range y.Content {
+			n.items = append(n.items, fromYamlNode(c))
+		}
+	default:
+		n.value = y.Value
+	}
+	return
+}
+
+// parseJsonNode reads the next value of dec.
+// b is the whole input of dec, used to calculate the line numbers.
+func parseJsonNode(dec *json.Decoder, b []byte) (n *node, err error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return
+	}
+
+	n = &node{line: lineAt(b, dec.InputOffset()-1)}
+	delim, ok := tok.(json.Delim)
+	if !ok {
+		n.value = tok
+		return
+	}
+
+	switch delim {
+	case '{':
+		n.kind = nodeObject
+		for dec.More() {
+			tok, err = dec.Token()
+			if err != nil {
+				return
+			}
+			f := nodeField{key: fmt.Sprint(tok), line: lineAt(b, dec.InputOffset()-1)}
+			f.value, err = parseJsonNode(dec, b)
+			if err != nil {
+				return
+			}
+			n.fields = append(n.fields, f)
+		}
+	case '[':
+		n.kind = nodeArray
+		for dec.More() {
+			var item *node
+			item, err = parseJsonNode(dec, b)
+			if err != nil {
+				return
+			}
+			n.items = append(n.items, item)
+		}
+	}
+
+	// Consume the closing delimiter.
+	_, err = dec.Token()
+	return
+}
+
+// lineAt returns the line number of the given byte offset inside b, starting at 1.
+func lineAt(b []byte, offset int64) int {
+	if offset < 0 {
+		offset = 0
+	}
+	if offset > int64(len(b)) {
+		offset = int64(len(b))
+	}
+	return bytes.Count(b[:offset], []byte("\n")) + 1
+}
+
+// The lineError type is a decoding error bound to a line.
+type lineError struct {
+	line int
+	msg  string
+}
+
+// decodeErrors splits the given json or yaml decoding error into errors bound to their lines.
+// Line is 0 if the position could not be determined.
+func decodeErrors(b []byte, err error) (errs []lineError) {
+	var (
+		syntaxErr *json.SyntaxError
+		typeErr   *json.UnmarshalTypeError
+		yamlErr   *yaml.TypeError
+		tomlErr   toml.ParseError
+	)
+	switch {
+	case errors.As(err, &tomlErr):
+		return []lineError{{line: tomlErr.Position.Line, msg: tomlErr.Message}}
+	case errors.As(err, &syntaxErr):
+		return []lineError{{line: lineAt(b, syntaxErr.Offset), msg: syntaxErr.Error()}}
+	case errors.As(err, &typeErr):
+		msg := fmt.Sprintf("cannot use %s as %s", typeErr.Value, typeErr.Type)
+		if typeErr.Field != "" {
+			msg += " in " + typeErr.Field
+		}
+		return []lineError{{line: lineAt(b, typeErr.Offset), msg: msg}}
+	case errors.As(err, &yamlErr):
+		for _, e := range yamlErr.Errors {
+			errs = append(errs, yamlLineError(e))
+		}
+		return
+	}
+	return []lineError{yamlLineError(strings.TrimPrefix(err.Error(), "yaml: "))}
+}
+
+// yamlLineError extracts the line of a yaml error message.
+func yamlLineError(msg string) (e lineError) {
+	e.msg = msg
+	match := yamlLineReg.FindStringSubmatch(msg)
+	if len(match) > 2 {
+		e.line, _ = strconv.Atoi(match[1])
+		e.msg = match[2]
+	}
+	return
+}
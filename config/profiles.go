@@ -0,0 +1,224 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+const (
+	// EnvProfile is the environment variable selecting the profile applied to the config.
+	EnvProfile = "WRAPNGO_PROFILE"
+
+	// OriginProfile is the origin of a setting read from the selected profile.
+	OriginProfile = "profile"
+)
+
+// ErrUnknownProfile is returned if the selected profile is not defined in any file.
+var ErrUnknownProfile = errors.New("unknown profile")
+
+var profileOverride string
+
+// The Profile type contains the values overlaid onto the config if the profile is selected (see SetProfile).
+// A profile can be defined in every file, profiles with the same name are merged.
+type Profile struct {
+	// GeneralSettings contains the settings replaced by the profile.
+	GeneralSettings ProfileSettings `json:"GeneralSettings" yaml:"GeneralSettings" toml:"GeneralSettings"`
+
+	// GlobalDynamic contains the values replacing or extending the ones of GlobalDynamic.
+	GlobalDynamic map[string]any `json:"GlobalDynamic" yaml:"GlobalDynamic" toml:"GlobalDynamic"`
+
+	// Dynamic contains the values replacing or extending the Dynamic of each task, keyed by the task name.
+	Dynamic map[string]map[string]any `json:"Dynamic" yaml:"Dynamic" toml:"Dynamic"`
+}
+
+// The ProfileSettings type contains the GeneralSettings a profile can replace, only the ones set are applied.
+// The settings affecting how the files are loaded and the tasks are resolved can not be changed by a profile.
+type ProfileSettings struct {
+	GlobalCommand string `json:"GlobalCommand" yaml:"GlobalCommand" toml:"GlobalCommand"`
+	Debug         *bool  `json:"Debug" yaml:"Debug" toml:"Debug"`
+	DateFormat    string `json:"DateFormat" yaml:"DateFormat" toml:"DateFormat"`
+}
+
+// SetProfile selects the profile applied by LoadAll.
+// It takes precedence over the WRAPNGO_PROFILE environment variable.
+func SetProfile(name string) {
+	profileOverride = name
+}
+
+// SelectedProfile returns the name of the profile selected via SetProfile or WRAPNGO_PROFILE.
+// It is empty if no profile has been selected.
+func SelectedProfile() string {
+	if profileOverride != "" {
+		return profileOverride
+	}
+	return os.Getenv(EnvProfile)
+}
+
+// addProfiles merges the given profiles loaded from path into the ones of c.
+// Values already set by another file are kept, just like the ones of GlobalDynamic.
+// This implementation is not thread-safe.
+func (c *Config) addProfiles(path string, profiles map[string]Profile) {
+	mergeProfiles(c.Profiles, c.profileSources, path, profiles, func(_, key, src string) {
+		log.Printf("Profile value '%s' of %s has already been set in %s, skipping\n", key, path, src)
+	})
+}
+
+// mergeProfiles merges the given profiles loaded from path into dst, sources contains the file of each merged value.
+// The keys of sources are "<profile>.<path>", e.g. "prod.GlobalDynamic.Host".
+// Values already set by another file are kept, skipped is called for each of them.
+func mergeProfiles(dst map[string]Profile, sources map[string]string, path string, profiles map[string]Profile, skipped func(profile, key, src string)) {
+	for _, name := range sortedProfileNames(profiles) {
+		p := profiles[name]
+		merged := dst[name]
+		add := func(key string) bool {
+			key = name + "." + key
+			src, ok := sources[key]
+			if ok {
+				skipped(name, key, src)
+				return false
+			}
+			sources[key] = path
+			return true
+		}
+
+		v := reflect.ValueOf(p.GeneralSettings)
+		mv := reflect.ValueOf(&merged.GeneralSettings).Elem()
+		for i := 0; i < v.NumField(); i++ {
+			if !v.Field(i).IsZero() && add("GeneralSettings."+v.Type().Field(i).Name) {
+				mv.Field(i).Set(v.Field(i))
+			}
+		}
+		for _, k := range sortedAnyKeys(p.GlobalDynamic) {
+			if !add("GlobalDynamic." + k) {
+				continue
+			}
+			if merged.GlobalDynamic == nil {
+				merged.GlobalDynamic = make(map[string]any)
+			}
+			merged.GlobalDynamic[k] = p.GlobalDynamic[k]
+		}
+		for task, values := range p.Dynamic {
+			for _, k := range sortedAnyKeys(values) {
+				if !add("Dynamic." + task + "." + k) {
+					continue
+				}
+				if merged.Dynamic == nil {
+					merged.Dynamic = make(map[string]map[string]any)
+				}
+				if merged.Dynamic[task] == nil {
+					merged.Dynamic[task] = make(map[string]any)
+				}
+				merged.Dynamic[task][k] = values[k]
+			}
+		}
+		dst[name] = merged
+	}
+}
+
+// applyProfile overlays the selected profile onto the GeneralSettings and GlobalDynamic of c,
+// the Dynamic values of the tasks are added by resolveTasks.
+// The returned origins contain the settings replaced by the profile.
+// This implementation is not thread-safe.
+func (c *Config) applyProfile() (origins map[string]Setting, err error) {
+	c.profile = SelectedProfile()
+	if c.profile == "" {
+		return
+	}
+	p, err := selectProfile(c.Profiles, c.profile)
+	if err != nil {
+		return
+	}
+
+	origins = make(map[string]Setting)
+	for _, name := range p.applySettings(&c.GeneralSettings) {
+		origins[name] = Setting{
+			Origin: OriginProfile,
+			Source: c.profile + ", " + c.profileSources[c.profile+".GeneralSettings."+name],
+		}
+	}
+
+	for k, value := range p.GlobalDynamic {
+		c.GlobalDynamic[k] = value
+		c.globalDynamicSources[k] = c.profileSources[c.profile+".GlobalDynamic."+k]
+	}
+	return
+}
+
+// selectProfile returns the profile with the given name.
+func selectProfile(profiles map[string]Profile, name string) (p Profile, err error) {
+	p, ok := profiles[name]
+	if !ok {
+		names := sortedProfileNames(profiles)
+		if len(names) == 0 {
+			return p, fmt.Errorf("%w %q, no profiles are defined", ErrUnknownProfile, name)
+		}
+		return p, fmt.Errorf("%w %q, defined profiles: %s", ErrUnknownProfile, name, strings.Join(names, ", "))
+	}
+	return
+}
+
+// applySettings replaces the values of s with the ones set inside the profile and returns their names.
+func (p Profile) applySettings(s *GeneralSettings) (names []string) {
+	v := reflect.ValueOf(p.GeneralSettings)
+	sv := reflect.ValueOf(s).Elem()
+	for i := 0; i < v.NumField(); i++ {
+		f := v.Field(i)
+		if f.IsZero() {
+			continue
+		}
+		name := v.Type().Field(i).Name
+		sv.FieldByName(name).Set(reflect.Indirect(f))
+		names = append(names, name)
+	}
+	return
+}
+
+// applyTask returns t with the Dynamic values of the profile for t, the task names are compared according to s.
+// If multiple names match t (e.g. Backup and backup), they are applied in lexical order, the values of the last one win.
+func (p Profile) applyTask(t Task, s GeneralSettings) Task {
+	names := make([]string, 0, len(p.Dynamic))
+	for name := range p.Dynamic {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	key := s.taskKey(t.Name)
+	for _, name := range names {
+		values := p.Dynamic[name]
+		if s.taskKey(name) != key || len(values) == 0 {
+			continue
+		}
+		dynamic := make(map[string]any, len(t.Dynamic)+len(values))
+		for k, v := range t.Dynamic {
+			dynamic[k] = v
+		}
+		for k, v := range values {
+			dynamic[k] = v
+		}
+		t.Dynamic = dynamic
+	}
+	return t
+}
+
+// sortedProfileNames returns the names of the given profiles in lexical order.
+func sortedProfileNames(profiles map[string]Profile) (names []string) {
+	for name := range profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return
+}
+
+// sortedAnyKeys returns the keys of m in lexical order.
+func sortedAnyKeys(m map[string]any) (keys []string) {
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return
+}
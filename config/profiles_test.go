@@ -0,0 +1,153 @@
+package config
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestProfiles(t *testing.T) {
+	files := map[string]string{
+		"config.yaml": `Version: 1
+GeneralSettings:
+  GlobalCommand: echo
+  DateFormat: YYYY
+GlobalDynamic:
+  Host: localhost
+  Port: 80
+Profiles:
+  prod:
+    GeneralSettings:
+      GlobalCommand: restic
+    GlobalDynamic:
+      Host: prod.example.com
+    Dynamic:
+      Backup:
+        Target: /mnt/prod
+Tasks:
+  - Name: backup
+    Dynamic:
+      Target: /tmp
+      Keep: 3
+`,
+		"other.yaml": `Version: 1
+Profiles:
+  prod:
+    GlobalDynamic:
+      Host: ignored
+      User: admin
+  staging:
+    GlobalDynamic:
+      Host: staging.example.com
+`,
+	}
+	tests := []struct {
+		name          string
+		flag          string
+		env           string
+		command       string
+		globalDynamic map[string]any
+		dynamic       map[string]any
+		err           error
+	}{
+		{
+			name:          "no profile",
+			command:       "echo",
+			globalDynamic: map[string]any{"Host": "localhost", "Port": 80},
+			dynamic:       map[string]any{"Target": "/tmp", "Keep": 3},
+		},
+		{
+			name:          "profile merged from multiple files",
+			env:           "prod",
+			command:       "restic",
+			globalDynamic: map[string]any{"Host": "prod.example.com", "Port": 80, "User": "admin"},
+			dynamic:       map[string]any{"Target": "/mnt/prod", "Keep": 3},
+		},
+		{
+			name:          "flag takes precedence over env",
+			flag:          "staging",
+			env:           "prod",
+			command:       "echo",
+			globalDynamic: map[string]any{"Host": "staging.example.com", "Port": 80},
+			dynamic:       map[string]any{"Target": "/tmp", "Keep": 3},
+		},
+		{
+			name: "unknown profile",
+			env:  "missing",
+			err:  ErrUnknownProfile,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setupConfigDir(t, files, withProfile(tt.flag))
+			t.Setenv(EnvProfile, tt.env)
+			conf, err := Reload()
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("error = %v, want %v", err, tt.err)
+			}
+			if err != nil {
+				return
+			}
+			if conf.Profile() != SelectedProfile() {
+				t.Errorf("Profile() = %q, want %q", conf.Profile(), SelectedProfile())
+			}
+			if cmd := conf.GeneralSettings().GlobalCommand; cmd != tt.command {
+				t.Errorf("GlobalCommand = %q, want %q", cmd, tt.command)
+			}
+			if g := conf.GlobalDynamic(); !reflect.DeepEqual(g, tt.globalDynamic) {
+				t.Errorf("GlobalDynamic = %v, want %v", g, tt.globalDynamic)
+			}
+			task, ok := conf.Task("backup")
+			if !ok {
+				t.Fatal("task backup not found")
+			}
+			if !reflect.DeepEqual(task.Dynamic, tt.dynamic) {
+				t.Errorf("Dynamic = %v, want %v", task.Dynamic, tt.dynamic)
+			}
+		})
+	}
+}
+
+func TestProfileApplyTaskOrder(t *testing.T) {
+	p := Profile{Dynamic: map[string]map[string]any{
+		"Backup": {"Target": "upper", "Upper": true},
+		"backup": {"Target": "lower"},
+		"BACKUP": {"Target": "capitals", "Capitals": true},
+		"upload": {"Target": "other"},
+	}}
+	tests := []struct {
+		name          string
+		task          string
+		caseSensitive bool
+		want          map[string]any
+	}{
+		{
+			name: "case-insensitive names are applied in lexical order",
+			task: "backup",
+			want: map[string]any{"Target": "lower", "Upper": true, "Capitals": true, "Keep": 3},
+		},
+		{
+			name:          "case-sensitive names",
+			task:          "Backup",
+			caseSensitive: true,
+			want:          map[string]any{"Target": "upper", "Upper": true, "Keep": 3},
+		},
+		{
+			name:          "no match",
+			task:          "restore",
+			caseSensitive: true,
+			want:          map[string]any{"Keep": 3},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Map iteration is random, a single run could pass by chance.
+			for i := 0; i < 20; i++ {
+				task := p.applyTask(Task{Name: tt.task, Dynamic: map[string]any{"Keep": 3}}, GeneralSettings{CaseSensitiveJobNames: tt.caseSensitive})
+				if !reflect.DeepEqual(task.Dynamic, tt.want) {
+					t.Fatalf("Dynamic = %v, want %v", task.Dynamic, tt.want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,207 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+)
+
+const (
+	// schemaDialect is the JSON Schema draft of the generated schema, it is supported by most editors.
+	schemaDialect = "http://json-schema.org/draft-07/schema#"
+
+	// schemaKey is the key a JSON file uses to reference its schema, it is allowed in every config file.
+	schemaKey = "$schema"
+)
+
+// schemaDescriptions contains the description of each field, keyed by "<type>.<field>".
+var schemaDescriptions = map[string]string{
+	"Config.Version":         "The version of the config structure the file has been written for, files without it are treated as version 0.",
+	"Config.GeneralSettings": "The settings of the program, only read from the main config.",
+	"Config.Defaults":        "The default values of the tasks inside the same file.",
+	"Config.Include":         "The files (or glob patterns) to load after the main config, only read from the main config.",
+	"Config.GlobalDynamic":   "Values which can be used by every task via %GlobalDynamic.<name>% placeholders.",
+	"Config.Profiles":        "Named sets of values overlaid onto the config if selected via -profile or WRAPNGO_PROFILE.",
+	"Config.Tasks":           "The tasks which can be run.",
+
+	"GeneralSettings.GlobalCommand":         "The command used by every task without a Command and by all operations of such a task.",
+	"GeneralSettings.Debug":                 "Whether to print debug information.",
+	"GeneralSettings.CaseSensitiveJobNames": "Whether task names and selectors are compared case-sensitively.",
+	"GeneralSettings.DateFormat":            "The format of the %Date% placeholder, e.g. YYYY-MM-DD_hh-mm-ss.",
+	"GeneralSettings.DuplicateTaskPolicy":   "How tasks with the same name are handled.",
+	"GeneralSettings.DisableConfigDirScan":  "Whether to only load the main config and its Include list instead of every file inside the config directory.",
+
+	"FileDefaults.Command":    "The command of every task and operation inside the same file without a Command.",
+	"FileDefaults.DateFormat": "The format of the %Date% placeholder for every task inside the same file.",
+	"FileDefaults.Dynamic":    "Values added to the Dynamic of every task inside the same file, values set by the task win.",
+
+	"Profile.GeneralSettings":       "The general settings replaced by the profile.",
+	"Profile.GlobalDynamic":         "Values replacing or extending the ones of GlobalDynamic.",
+	"Profile.Dynamic":               "Values replacing or extending the Dynamic of each task, keyed by the task name.",
+	"ProfileSettings.GlobalCommand": "Replaces GeneralSettings.GlobalCommand.",
+	"ProfileSettings.Debug":         "Replaces GeneralSettings.Debug.",
+	"ProfileSettings.DateFormat":    "Replaces GeneralSettings.DateFormat.",
+
+	"Task.Name":                        "The name of the task, used to run it.",
+	"Task.Extends":                     "The name of the task to inherit every unset value from.",
+	"Task.Abstract":                    "Whether the task can only be extended by other tasks and is never run or listed.",
+	"Task.Tags":                        "Tags to select multiple tasks at once (tag:<name>).",
+	"Task.DependsOn":                   "The names of the tasks which have to succeed before the task is started.",
+	"Task.Command":                     "The command, script or executable of the job.",
+	"Task.Dynamic":                     "Values which can be used via %Dynamic.<name>% placeholders.",
+	"Task.Arguments":                   "The arguments of the job's Command.",
+	"Task.Environment":                 "Environment variables added to the job and every operation, the values can contain placeholders.",
+	"Task.StopIfUnsuccessful":          "Whether the task stops and fails if the compression or the job fails, the PostOperations are skipped. Otherwise the failure is logged as a warning and the task continues.",
+	"Task.RemovePathAfterJobCompletes": "The path to remove after the job completed.",
+	"Task.AllowParallelOperationsRun":  "Whether the PreOperations run in parallel to the job.",
+	"Task.Compression":                 "Compresses a path into a *.tar.gz archive before the job starts.",
+	"Task.PreOperations":               "The operations run before the job.",
+	"Task.PostOperations":              "The operations run after the job.",
+
+	"CompressionOptions.PathToCompress":           "The path to compress, compression is disabled if empty.",
+	"CompressionOptions.OutputPath":               "The path of the archive file, <parent>/<name>-<date>.tar.gz next to PathToCompress is used if empty.",
+	"CompressionOptions.InMemoryCompressionLimit": "The maximum size compressed in memory, e.g. 512MB or 1GB. Larger paths are streamed into the archive file.",
+	"CompressionOptions.OverwriteCompressed":      "Whether an existing archive is overwritten.",
+	"CompressionOptions.RetainStructure":          "Whether the archive keeps the path of PathToCompress or only its content.",
+
+	"Operation.Enabled":             "Whether the operation is run.",
+	"Operation.StopIfUnsuccessful":  "Whether the task stops and fails if the operation fails, a failing parallel PreOperation also stops the job. Otherwise the failure is logged as a warning and the task continues.",
+	"Operation.SecondsUntilTimeout": "The seconds after which the operation is considered as failed.",
+	"Operation.IgnoreTimeout":       "Whether SecondsUntilTimeout is ignored.",
+	"Operation.CaptureStdOut":       "Whether the output of the operation is logged.",
+	"Operation.Command":             "The command, script or executable of the operation, ignored if the task has no Command.",
+	"Operation.Arguments":           "The arguments of the operation's Command.",
+}
+
+// The schema type is a single JSON Schema, only the keywords needed to describe the config are supported.
+type schema struct {
+	Dialect              string      `json:"$schema,omitempty"`
+	Title                string      `json:"title,omitempty"`
+	Description          string      `json:"description,omitempty"`
+	Ref                  string      `json:"$ref,omitempty"`
+	AllOf                []*schema   `json:"allOf,omitempty"`
+	Type                 any         `json:"type,omitempty"`
+	Enum                 []string    `json:"enum,omitempty"`
+	Pattern              string      `json:"pattern,omitempty"`
+	Minimum              *int        `json:"minimum,omitempty"`
+	Maximum              *int        `json:"maximum,omitempty"`
+	Items                *schema     `json:"items,omitempty"`
+	Required             []string    `json:"required,omitempty"`
+	Properties           *orderedMap `json:"properties,omitempty"`
+	AdditionalProperties any         `json:"additionalProperties,omitempty"`
+	Definitions          *orderedMap `json:"definitions,omitempty"`
+}
+
+// Schema returns the JSON Schema of the config files, derived from the Config structure.
+// Editors use it to validate and complete JSON and YAML files.
+func Schema() (b []byte, err error) {
+	g := schemaGenerator{definitions: &orderedMap{values: make(map[string]any)}}
+	root := g.object(reflect.TypeOf(Config{}))
+	root.Dialect = schemaDialect
+	root.Title = "WrapNGo configuration"
+	root.Properties.keys = append([]string{schemaKey}, root.Properties.keys...)
+	root.Properties.values[schemaKey] = &schema{Type: "string", Description: "The JSON Schema of the file, ignored by WrapNGo."}
+	root.Definitions = g.definitions
+
+	buf := &bytes.Buffer{}
+	enc := json.NewEncoder(buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	err = enc.Encode(root)
+	return buf.Bytes(), err
+}
+
+// The schemaGenerator type collects the definitions of the structs referenced by the schema.
+type schemaGenerator struct {
+	definitions *orderedMap
+}
+
+// object returns the schema of the struct t, its fields are listed in the order they are declared.
+func (g schemaGenerator) object(t reflect.Type) *schema {
+	s := &schema{Type: "object", Properties: &orderedMap{values: make(map[string]any)}, AdditionalProperties: false}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() || f.Tag.Get("json") == "-" {
+			continue
+		}
+		fs := g.field(t.Name(), f)
+		s.Properties.keys = append(s.Properties.keys, f.Name)
+		s.Properties.values[f.Name] = fs
+	}
+	if t == reflect.TypeOf(Task{}) {
+		s.Required = []string{"Name"}
+	}
+	return s
+}
+
+// field returns the schema of the field f of the struct named parent, including its description and constraints.
+// Draft-07 ignores every keyword next to "$ref", references are wrapped into "allOf" to keep the description.
+func (g schemaGenerator) field(parent string, f reflect.StructField) (s *schema) {
+	s = g.of(f.Type)
+	if s.Ref != "" {
+		s = &schema{AllOf: []*schema{s}}
+	}
+	s.Description = schemaDescriptions[parent+"."+f.Name]
+	switch parent + "." + f.Name {
+	case "Config.Version":
+		s.Minimum, s.Maximum = intPtr(0), intPtr(CurrentVersion)
+	case "GeneralSettings.DuplicateTaskPolicy":
+		// An empty policy is the same as DuplicateTaskWarn.
+		s.Enum = append([]string{""}, DuplicateTaskPolicies()...)
+	case "CompressionOptions.InMemoryCompressionLimit":
+		// Values containing a placeholder are resolved at run time, an empty value uses the default limit.
+		s.Pattern = `^(\s*\d+[bBkKmMgG][bB]?\s*|.*%.*)?$`
+	case "Operation.SecondsUntilTimeout":
+		s.Minimum = intPtr(0)
+	}
+	return
+}
+
+// of returns the schema of a value of type t.
+// Structs are added to the definitions and referenced, any other type is described inline.
+// Lists, maps and pointers may be null, the encoders write unset ones as null.
+func (g schemaGenerator) of(t reflect.Type) *schema {
+	if t.Kind() == reflect.Pointer {
+		s := g.of(t.Elem())
+		typ, ok := s.Type.(string)
+		if ok {
+			s.Type = []string{typ, "null"}
+		}
+		return s
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		_, ok := g.definitions.values[t.Name()]
+		if !ok {
+			// Reserve the name first, a struct may reference itself.
+			g.definitions.keys = append(g.definitions.keys, t.Name())
+			g.definitions.values[t.Name()] = nil
+			g.definitions.values[t.Name()] = g.object(t)
+		}
+		return &schema{Ref: "#/definitions/" + t.Name()}
+	case reflect.Slice:
+		return &schema{Type: []string{"array", "null"}, Items: g.of(t.Elem())}
+	case reflect.Map:
+		s := &schema{Type: []string{"object", "null"}, AdditionalProperties: true}
+		if t.Elem().Kind() != reflect.Interface {
+			s.AdditionalProperties = g.of(t.Elem())
+		}
+		return s
+	case reflect.String:
+		return &schema{Type: "string"}
+	case reflect.Bool:
+		return &schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &schema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &schema{Type: "number"}
+	}
+	return &schema{}
+}
+
+// intPtr returns a pointer to v.
+func intPtr(v int) *int {
+	return &v
+}
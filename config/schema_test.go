@@ -0,0 +1,43 @@
+package config
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestSchemaReferences(t *testing.T) {
+	b, err := Schema()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var root map[string]any
+	err = json.Unmarshal(b, &root)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Draft-07 ignores every keyword next to "$ref".
+	var walk func(path string, v any)
+	walk = func(path string, v any) {
+		switch val := v.(type) {
+		case map[string]any:
+			if _, ok := val["$ref"]; ok && len(val) > 1 {
+				t.Errorf("%s: keywords next to $ref: %v", path, val)
+			}
+			for k, child := range val {
+				walk(path+"/"+k, child)
+			}
+		case []any:
+			for _, child := range val {
+				walk(path, child)
+			}
+		}
+	}
+	walk("#", root)
+
+	props := root["definitions"].(map[string]any)["Task"].(map[string]any)["properties"].(map[string]any)
+	compression := props["Compression"].(map[string]any)
+	if compression["description"] != schemaDescriptions["Task.Compression"] {
+		t.Errorf("Compression = %v, want its description next to allOf", compression)
+	}
+}
@@ -0,0 +1,183 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+const (
+	// EnvPrefix is the prefix of every environment variable overriding a setting, e.g. WRAPNGO_DATE_FORMAT.
+	EnvPrefix = "WRAPNGO_"
+
+	// OriginDefault is the origin of a setting which has not been set anywhere.
+	OriginDefault = "default"
+
+	// OriginFile is the origin of a setting read from the main config.
+	OriginFile = "file"
+
+	// OriginEnv is the origin of a setting read from its environment variable.
+	OriginEnv = "env"
+
+	// OriginFlag is the origin of a setting read from its command line flag.
+	OriginFlag = "flag"
+)
+
+// ErrInvalidSetting is returned if the override of a setting can not be parsed.
+var ErrInvalidSetting = errors.New("invalid setting")
+
+var (
+	overrideMux      sync.Mutex
+	settingOverrides = make(map[string]string)
+)
+
+// The Setting type describes the effective value of a single field of GeneralSettings.
+type Setting struct {
+	// Name is the name of the field, e.g. "DateFormat".
+	Name string `json:"Name" yaml:"Name"`
+
+	// Value is the effective value.
+	Value any `json:"Value" yaml:"Value"`
+
+	// Origin is one of OriginDefault, OriginFile, OriginProfile, OriginEnv and OriginFlag.
+	Origin string `json:"Origin" yaml:"Origin"`
+
+	// Source is the file, environment variable or flag the value has been read from.
+	// Values of a profile contain the name of the profile followed by its file.
+	// It is empty for OriginDefault.
+	Source string `json:"Source,omitempty" yaml:"Source,omitempty"`
+}
+
+// SettingNames returns the names of all fields of GeneralSettings.
+func SettingNames() (names []string) {
+	t := reflect.TypeOf(GeneralSettings{})
+	for i := 0; i < t.NumField(); i++ {
+		if t.Field(i).IsExported() {
+			names = append(names, t.Field(i).Name)
+		}
+	}
+	return
+}
+
+// SettingEnv returns the environment variable overriding the given setting, e.g. WRAPNGO_DATE_FORMAT for DateFormat.
+func SettingEnv(name string) string {
+	return EnvPrefix + strings.ToUpper(strings.Join(splitWords(name), "_"))
+}
+
+// SettingFlag returns the name of the command line flag overriding the given setting, e.g. date-format for DateFormat.
+func SettingFlag(name string) string {
+	return strings.ToLower(strings.Join(splitWords(name), "-"))
+}
+
+// SettingIsBool returns whether the given setting is a boolean.
+func SettingIsBool(name string) bool {
+	f, ok := reflect.TypeOf(GeneralSettings{}).FieldByName(name)
+	return ok && f.Type.Kind() == reflect.Bool
+}
+
+// OverrideSetting overrides the given setting with a value from the command line.
+// It takes precedence over the environment variable and the main config and is applied by LoadAll.
+func OverrideSetting(name, value string) (err error) {
+	var s GeneralSettings
+	f := reflect.ValueOf(&s).Elem().FieldByName(name)
+	if !f.IsValid() {
+		return fmt.Errorf("%w: unknown setting %s", ErrInvalidSetting, name)
+	}
+	err = setSetting(f, value)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidSetting, err)
+	}
+
+	overrideMux.Lock()
+	defer overrideMux.Unlock()
+	settingOverrides[name] = value
+	return
+}
+
+// Settings returns the effective settings of the current snapshot in the order they are declared.
+func Settings() []Setting {
+	return CurrentSnapshot().Settings()
+}
+
+// applySettings overrides the values of s with their environment variables and command line flags.
+// origins contains the origin of the settings already set, e.g. the ones read from the main config.
+// The returned settings contain the effective value of every field together with its origin.
+func applySettings(s GeneralSettings, origins map[string]Setting) (applied GeneralSettings, settings []Setting, err error) {
+	overrideMux.Lock()
+	defer overrideMux.Unlock()
+
+	v := reflect.ValueOf(&s).Elem()
+	for _, name := range SettingNames() {
+		f := v.FieldByName(name)
+		setting := Setting{Name: name, Origin: OriginDefault}
+		o, ok := origins[name]
+		if ok {
+			setting.Origin, setting.Source = o.Origin, o.Source
+		}
+
+		env := SettingEnv(name)
+		value := os.Getenv(env)
+		if value != "" {
+			err = setSetting(f, value)
+			if err != nil {
+				return s, nil, fmt.Errorf("%w: %s: %v", ErrInvalidSetting, env, err)
+			}
+			setting.Origin, setting.Source = OriginEnv, env
+		}
+
+		value, ok = settingOverrides[name]
+		if ok {
+			// Already validated by OverrideSetting.
+			_ = setSetting(f, value)
+			setting.Origin, setting.Source = OriginFlag, "-"+SettingFlag(name)
+		}
+
+		// An empty policy is the same as DuplicateTaskWarn, report the policy which is used.
+		if name == "DuplicateTaskPolicy" && f.String() == "" {
+			f.SetString(DuplicateTaskWarn)
+		}
+		setting.Value = f.Interface()
+		settings = append(settings, setting)
+	}
+	return s, settings, nil
+}
+
+// setSetting parses value into the given field.
+func setSetting(f reflect.Value, value string) (err error) {
+	switch f.Kind() {
+	case reflect.Bool:
+		var b bool
+		b, err = strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("%q is not a boolean", value)
+		}
+		f.SetBool(b)
+	case reflect.String:
+		f.SetString(value)
+	default:
+		return fmt.Errorf("settings of type %s can not be overridden", f.Type())
+	}
+	return
+}
+
+// splitWords splits a camel-cased name into its words, e.g. "CaseSensitiveJobNames" into "Case", "Sensitive", "Job", "Names".
+func splitWords(name string) (words []string) {
+	runes := []rune(name)
+	start := 0
+	for i := 1; i < len(runes); i++ {
+		if !unicode.IsUpper(runes[i]) {
+			continue
+		}
+		// Keep acronyms together, only split before their last letter if a lower-case one follows.
+		if unicode.IsLower(runes[i-1]) || (i+1 < len(runes) && unicode.IsLower(runes[i+1])) {
+			words = append(words, string(runes[start:i]))
+			start = i
+		}
+	}
+	return append(words, string(runes[start:]))
+}
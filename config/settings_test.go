@@ -0,0 +1,157 @@
+package config
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestSettingNames(t *testing.T) {
+	tests := []struct {
+		name string
+		env  string
+		flag string
+	}{
+		{name: "Debug", env: "WRAPNGO_DEBUG", flag: "debug"},
+		{name: "DateFormat", env: "WRAPNGO_DATE_FORMAT", flag: "date-format"},
+		{name: "CaseSensitiveJobNames", env: "WRAPNGO_CASE_SENSITIVE_JOB_NAMES", flag: "case-sensitive-job-names"},
+		{name: "DisableConfigDirScan", env: "WRAPNGO_DISABLE_CONFIG_DIR_SCAN", flag: "disable-config-dir-scan"},
+		{name: "HTTPProxy", env: "WRAPNGO_HTTP_PROXY", flag: "http-proxy"},
+		{name: "ProxyURL", env: "WRAPNGO_PROXY_URL", flag: "proxy-url"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := SettingEnv(tt.name); got != tt.env {
+				t.Errorf("SettingEnv() = %q, want %q", got, tt.env)
+			}
+			if got := SettingFlag(tt.name); got != tt.flag {
+				t.Errorf("SettingFlag() = %q, want %q", got, tt.flag)
+			}
+		})
+	}
+}
+
+func TestOverrideSetting(t *testing.T) {
+	t.Cleanup(resetSettingOverrides)
+	tests := []struct {
+		name    string
+		setting string
+		value   string
+		err     bool
+	}{
+		{name: "string", setting: "DateFormat", value: "YYYY"},
+		{name: "bool", setting: "Debug", value: "true"},
+		{name: "bool shorthand", setting: "Debug", value: "1"},
+		{name: "invalid bool", setting: "Debug", value: "yes please", err: true},
+		{name: "unknown setting", setting: "Missing", value: "x", err: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := OverrideSetting(tt.setting, tt.value)
+			if tt.err != errors.Is(err, ErrInvalidSetting) {
+				t.Errorf("error = %v, error expected: %t", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestApplySettings(t *testing.T) {
+	file := Setting{Origin: OriginFile, Source: "config.json"}
+	tests := []struct {
+		name      string
+		settings  GeneralSettings
+		origins   map[string]Setting
+		env       map[string]string
+		overrides map[string]string
+		want      Setting
+		err       bool
+	}{
+		{
+			name: "default",
+			want: Setting{Name: "DateFormat", Value: "", Origin: OriginDefault},
+		},
+		{
+			name:     "file",
+			settings: GeneralSettings{DateFormat: "YYYY"},
+			origins:  map[string]Setting{"DateFormat": file},
+			want:     Setting{Name: "DateFormat", Value: "YYYY", Origin: OriginFile, Source: "config.json"},
+		},
+		{
+			name:     "env wins over the file",
+			settings: GeneralSettings{DateFormat: "YYYY"},
+			origins:  map[string]Setting{"DateFormat": file},
+			env:      map[string]string{"WRAPNGO_DATE_FORMAT": "MM"},
+			want:     Setting{Name: "DateFormat", Value: "MM", Origin: OriginEnv, Source: "WRAPNGO_DATE_FORMAT"},
+		},
+		{
+			name:      "flag wins over env",
+			env:       map[string]string{"WRAPNGO_DATE_FORMAT": "MM"},
+			overrides: map[string]string{"DateFormat": "DD"},
+			want:      Setting{Name: "DateFormat", Value: "DD", Origin: OriginFlag, Source: "-date-format"},
+		},
+		{
+			name: "effective default",
+			want: Setting{Name: "DuplicateTaskPolicy", Value: DuplicateTaskWarn, Origin: OriginDefault},
+		},
+		{
+			name: "bool env",
+			env:  map[string]string{"WRAPNGO_DEBUG": "true"},
+			want: Setting{Name: "Debug", Value: true, Origin: OriginEnv, Source: "WRAPNGO_DEBUG"},
+		},
+		{
+			name: "invalid bool env",
+			env:  map[string]string{"WRAPNGO_DEBUG": "maybe"},
+			err:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Cleanup(resetSettingOverrides)
+			for _, name := range SettingNames() {
+				t.Setenv(SettingEnv(name), "")
+			}
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+			for k, v := range tt.overrides {
+				err := OverrideSetting(k, v)
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			applied, settings, err := applySettings(tt.settings, tt.origins)
+			if tt.err {
+				if !errors.Is(err, ErrInvalidSetting) {
+					t.Errorf("error = %v, want %v", err, ErrInvalidSetting)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(settings) != len(SettingNames()) {
+				t.Errorf("got %d settings, want %d", len(settings), len(SettingNames()))
+			}
+			for _, s := range settings {
+				if s.Name != tt.want.Name {
+					continue
+				}
+				if !reflect.DeepEqual(s, tt.want) {
+					t.Errorf("setting = %+v, want %+v", s, tt.want)
+				}
+				got := reflect.ValueOf(applied).FieldByName(s.Name).Interface()
+				if got != tt.want.Value {
+					t.Errorf("applied %s = %v, want %v", s.Name, got, tt.want.Value)
+				}
+			}
+		})
+	}
+}
+
+// resetSettingOverrides removes every override set by OverrideSetting.
+func resetSettingOverrides() {
+	overrideMux.Lock()
+	defer overrideMux.Unlock()
+	settingOverrides = make(map[string]string)
+}
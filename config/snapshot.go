@@ -0,0 +1,211 @@
+package config
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// current contains the *Snapshot published last.
+var current atomic.Value
+
+// The Snapshot type is an immutable view of a loaded configuration.
+// A snapshot is never changed after it has been published, a reload publishes a new one instead.
+// Every method returns a copy, values may be modified by the caller without affecting the snapshot.
+type Snapshot struct {
+	settings      GeneralSettings
+	settingList   []Setting
+	globalDynamic map[string]any
+	tasks         []Task
+	profile       string
+
+	// byName contains the indices of the tasks for each task key (see GeneralSettings.taskKey).
+	byName map[string][]int
+}
+
+// CurrentSnapshot returns the snapshot of the config loaded last.
+// Before anything has been loaded, an empty snapshot is returned.
+func CurrentSnapshot() *Snapshot {
+	s, ok := current.Load().(*Snapshot)
+	if !ok {
+		return newSnapshot(newConfig())
+	}
+	return s
+}
+
+// publish replaces the current snapshot with a deep copy of c.
+// This implementation is not thread-safe regarding c.
+func publish(c *Config) *Snapshot {
+	s := newSnapshot(c)
+	current.Store(s)
+	return s
+}
+
+// newSnapshot creates a snapshot containing a deep copy of c.
+func newSnapshot(c *Config) (s *Snapshot) {
+	s = &Snapshot{
+		settings:      c.GeneralSettings,
+		settingList:   append([]Setting(nil), c.settings...),
+		globalDynamic: deepCopyMap(c.GlobalDynamic),
+		tasks:         make([]Task, len(c.Tasks)),
+		profile:       c.profile,
+		byName:        make(map[string][]int),
+	}
+	for i, t := range c.Tasks {
+		s.tasks[i] = t.clone()
+		key := s.settings.taskKey(t.Name)
+		s.byName[key] = append(s.byName[key], i)
+	}
+	return
+}
+
+// GeneralSettings returns the effective general settings.
+func (s *Snapshot) GeneralSettings() GeneralSettings {
+	return s.settings
+}
+
+// Settings returns the effective general settings together with their origin in the order they are declared.
+func (s *Snapshot) Settings() []Setting {
+	return append([]Setting(nil), s.settingList...)
+}
+
+// GlobalDynamic returns the GlobalDynamic values of all files.
+func (s *Snapshot) GlobalDynamic() map[string]any {
+	return deepCopyMap(s.globalDynamic)
+}
+
+// Profile returns the name of the applied profile, it is empty if no profile has been selected.
+func (s *Snapshot) Profile() string {
+	return s.profile
+}
+
+// Tasks returns every task in the order the tasks have been loaded.
+func (s *Snapshot) Tasks() []Task {
+	tasks := make([]Task, len(s.tasks))
+	for i, t := range s.tasks {
+		tasks[i] = t.clone()
+	}
+	return tasks
+}
+
+// Task returns the first task with the given name, honoring GeneralSettings.CaseSensitiveJobNames.
+func (s *Snapshot) Task(name string) (t Task, ok bool) {
+	indices := s.byName[s.settings.taskKey(name)]
+	if len(indices) == 0 {
+		return
+	}
+	return s.tasks[indices[0]].clone(), true
+}
+
+// TasksNamed returns every task with the given name, honoring GeneralSettings.CaseSensitiveJobNames.
+// Multiple tasks can share a name depending on GeneralSettings.DuplicateTaskPolicy.
+func (s *Snapshot) TasksNamed(name string) (tasks []Task) {
+	for _, i := range s.byName[s.settings.taskKey(name)] {
+		tasks = append(tasks, s.tasks[i].clone())
+	}
+	return
+}
+
+// WithGlobalDynamic returns a new snapshot whose GlobalDynamic contains the given values in addition.
+// Values of s with the same key are replaced, s itself is not changed.
+func (s *Snapshot) WithGlobalDynamic(values map[string]any) *Snapshot {
+	derived := *s
+	derived.globalDynamic = deepCopyMap(s.globalDynamic)
+	if derived.globalDynamic == nil {
+		derived.globalDynamic = make(map[string]any, len(values))
+	}
+	for k, v := range values {
+		derived.globalDynamic[k] = deepCopy(v)
+	}
+	return &derived
+}
+
+// config returns a deep copy of s as Config.
+func (s *Snapshot) config() Config {
+	return Config{
+		Version:         CurrentVersion,
+		GeneralSettings: s.settings,
+		GlobalDynamic:   s.GlobalDynamic(),
+		Tasks:           s.Tasks(),
+		Mutex:           &sync.Mutex{},
+		settings:        s.Settings(),
+		profile:         s.profile,
+	}
+}
+
+// clone returns a deep copy of t.
+func (t Task) clone() Task {
+	t.Tags = copyStrings(t.Tags)
+	t.DependsOn = copyStrings(t.DependsOn)
+	t.Arguments = copyStrings(t.Arguments)
+	t.Args = copyStrings(t.Args)
+	t.Dynamic = deepCopyMap(t.Dynamic)
+	t.PreOperations = copyOperations(t.PreOperations)
+	t.PostOperations = copyOperations(t.PostOperations)
+	t.raw = deepCopyMap(t.raw)
+	t.defaults.Dynamic = deepCopyMap(t.defaults.Dynamic)
+	if t.Environment != nil {
+		env := make(map[string]string, len(t.Environment))
+		for k, v := range t.Environment {
+			env[k] = v
+		}
+		t.Environment = env
+	}
+	return t
+}
+
+// copyOperations returns a deep copy of ops, nil stays nil.
+func copyOperations(ops []Operation) []Operation {
+	if ops == nil {
+		return nil
+	}
+	copied := make([]Operation, len(ops))
+	for i, o := range ops {
+		o.Arguments = copyStrings(o.Arguments)
+		copied[i] = o
+	}
+	return copied
+}
+
+// copyStrings returns a copy of values, nil stays nil.
+func copyStrings(values []string) []string {
+	if values == nil {
+		return nil
+	}
+	return append(make([]string, 0, len(values)), values...)
+}
+
+// deepCopyMap returns a deep copy of m, nil stays nil.
+func deepCopyMap(m map[string]any) map[string]any {
+	if m == nil {
+		return nil
+	}
+	return deepCopy(m).(map[string]any)
+}
+
+// deepCopy returns a deep copy of the maps and lists of a decoded value.
+// Every other value is immutable and returned as it is.
+func deepCopy(v any) any {
+	switch val := v.(type) {
+	case map[string]any:
+		m := make(map[string]any, len(val))
+		for k, item := range val {
+			m[k] = deepCopy(item)
+		}
+		return m
+	case []any:
+		items := make([]any, len(val))
+		for i, item := range val {
+			items[i] = deepCopy(item)
+		}
+		return items
+	case []map[string]any:
+		items := make([]map[string]any, len(val))
+		for i, item := range val {
+			items[i] = deepCopyMap(item)
+		}
+		return items
+	case []string:
+		return copyStrings(val)
+	}
+	return v
+}
@@ -0,0 +1,148 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+func TestSnapshotCopies(t *testing.T) {
+	conf := reloadFiles(t, map[string]string{
+		"config.yaml": `Version: 1
+GeneralSettings:
+  GlobalCommand: echo
+GlobalDynamic:
+  Host: prod
+  List: [a, b]
+Tasks:
+  - Name: backup
+    Arguments: [a]
+    Tags: [nightly]
+    Dynamic:
+      Nested:
+        Key: value
+    Environment:
+      KEY: value
+    PreOperations:
+      - Arguments: [op]
+`,
+	})
+
+	tests := []struct {
+		name   string
+		modify func(s *Snapshot)
+	}{
+		{name: "GlobalDynamic", modify: func(s *Snapshot) {
+			g := s.GlobalDynamic()
+			g["Host"] = "changed"
+			g["List"].([]any)[0] = "changed"
+		}},
+		{name: "Tasks", modify: func(s *Snapshot) {
+			tasks := s.Tasks()
+			tasks[0].Name = "changed"
+			tasks[0].Arguments[0] = "changed"
+			tasks[0].Tags[0] = "changed"
+			tasks[0].Dynamic["Nested"].(map[string]any)["Key"] = "changed"
+			tasks[0].Environment["KEY"] = "changed"
+			tasks[0].PreOperations[0].Arguments[0] = "changed"
+		}},
+		{name: "Task", modify: func(s *Snapshot) {
+			task, _ := s.Task("backup")
+			task.Arguments[0] = "changed"
+			task.Dynamic["Nested"].(map[string]any)["Key"] = "changed"
+		}},
+		{name: "WithGlobalDynamic", modify: func(s *Snapshot) {
+			derived := s.WithGlobalDynamic(map[string]any{"Host": "changed", "New": "value"})
+			derived.GlobalDynamic()["List"].([]any)[0] = "changed"
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.modify(conf)
+			g := conf.GlobalDynamic()
+			if g["Host"] != "prod" || g["List"].([]any)[0] != "a" || g["New"] != nil {
+				t.Errorf("GlobalDynamic has been modified: %v", g)
+			}
+			task, ok := conf.Task("backup")
+			if !ok {
+				t.Fatal("task backup not found")
+			}
+			switch {
+			case task.Arguments[0] != "a",
+				task.Tags[0] != "nightly",
+				task.Dynamic["Nested"].(map[string]any)["Key"] != "value",
+				task.Environment["KEY"] != "value",
+				task.PreOperations[0].Arguments[0] != "op":
+				t.Errorf("task has been modified: %+v", task)
+			}
+		})
+	}
+}
+
+func TestReloadPublishesNewSnapshot(t *testing.T) {
+	old := reloadFiles(t, map[string]string{
+		"config.yaml": "Version: 1\nGeneralSettings:\n  GlobalCommand: echo\nTasks:\n  - Name: a\n",
+	})
+	dir, err := Dir()
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "config.yaml")
+
+	tests := []struct {
+		name    string
+		content string
+		err     bool
+		tasks   int
+	}{
+		{name: "reload", content: "Version: 1\nGeneralSettings:\n  GlobalCommand: echo\nTasks:\n  - Name: a\n  - Name: b\n", tasks: 2},
+		{name: "failing reload keeps the current snapshot", content: "Tasks: [", err: true, tasks: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := os.WriteFile(path, []byte(tt.content), 0600)
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, err = Reload()
+			if (err != nil) != tt.err {
+				t.Fatalf("error = %v, error expected: %t", err, tt.err)
+			}
+			if n := len(CurrentSnapshot().Tasks()); n != tt.tasks {
+				t.Errorf("current snapshot has %d tasks, want %d", n, tt.tasks)
+			}
+			if n := len(old.Tasks()); n != 1 {
+				t.Errorf("previous snapshot has %d tasks, want 1", n)
+			}
+		})
+	}
+}
+
+func TestReloadWhileReading(t *testing.T) {
+	reloadFiles(t, map[string]string{
+		"config.yaml": "Version: 1\nGeneralSettings:\n  GlobalCommand: echo\nGlobalDynamic:\n  Host: prod\nTasks:\n  - Name: a\n  - Name: b\n",
+	})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				s := CurrentSnapshot()
+				if len(s.Tasks()) != 2 || s.GlobalDynamic()["Host"] != "prod" {
+					t.Errorf("inconsistent snapshot: %v, %v", s.Tasks(), s.GlobalDynamic())
+					return
+				}
+			}
+		}()
+	}
+	for i := 0; i < 10; i++ {
+		_, err := Reload()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	wg.Wait()
+}
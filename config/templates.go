@@ -0,0 +1,139 @@
+package config
+
+import (
+	"errors"
+	"sort"
+)
+
+const (
+	// TemplateDefault is the name of the template containing an example of every setting.
+	TemplateDefault = "default"
+)
+
+// ErrUnknownTemplate is returned if a config template does not exist.
+var ErrUnknownTemplate = errors.New("unknown template")
+
+// templates contains every config template which can be used to create a new config.
+var templates = map[string]func() *Config{
+	TemplateDefault: defaultConfig,
+	"minimal":       minimalConfig,
+	"backup":        backupConfig,
+	"service":       serviceConfig,
+}
+
+// TemplateNames returns the names of all available config templates.
+func TemplateNames() (names []string) {
+	for name := range templates {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return
+}
+
+// minimalConfig defines the smallest usable configuration.
+func minimalConfig() *Config {
+	return &Config{
+		Version: CurrentVersion,
+		GeneralSettings: GeneralSettings{
+			DateFormat: "YYYY-MM-DD_hh-mm-ss",
+		},
+		GlobalDynamic: map[string]any{},
+		Tasks: []Task{
+			{
+				Name:    "Hello",
+				Command: "echo",
+				Arguments: []string{
+					"Hello from " + formatPlaceholder("Name") + " at " + formatPlaceholder("Date"),
+				},
+			},
+		},
+	}
+}
+
+// backupConfig defines a configuration compressing a directory and copying the archive to a destination.
+func backupConfig() *Config {
+	return &Config{
+		Version: CurrentVersion,
+		GeneralSettings: GeneralSettings{
+			DateFormat: "YYYY-MM-DD_hh-mm-ss",
+		},
+		GlobalDynamic: map[string]any{
+			"BackupRoot": "/path/to/backups",
+		},
+		Tasks: []Task{
+			{
+				Name:               "Backup",
+				Tags:               []string{"nightly"},
+				Command:            "cp",
+				StopIfUnsuccessful: true,
+				Dynamic: map[string]any{
+					"Source": "/path/to/data",
+				},
+				Arguments: []string{
+					formatPlaceholder("Compression.PathToCompress"),
+					formatPlaceholder("GlobalDynamic.BackupRoot"),
+				},
+				RemovePathAfterJobCompletes: formatPlaceholder("Compression.PathToCompress"),
+				Compression: CompressionOptions{
+					PathToCompress:           formatPlaceholder("Dynamic.Source"),
+					InMemoryCompressionLimit: "512MB",
+					RetainStructure:          false,
+				},
+				PostOperations: []Operation{
+					{
+						Enabled:             false,
+						CaptureStdOut:       true,
+						Command:             "echo",
+						SecondsUntilTimeout: 10,
+						Arguments: []string{
+							"Backup of " + formatPlaceholder("Dynamic.Source") + " finished at " + formatPlaceholder("Date"),
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// serviceConfig defines a configuration wrapping a long-running program with a health check.
+func serviceConfig() *Config {
+	return &Config{
+		Version: CurrentVersion,
+		GeneralSettings: GeneralSettings{
+			DateFormat: "YYYY-MM-DD_hh-mm-ss",
+		},
+		GlobalDynamic: map[string]any{},
+		Tasks: []Task{
+			{
+				Name:               "Service",
+				Tags:               []string{"service"},
+				Command:            "/path/to/service",
+				StopIfUnsuccessful: true,
+				Dynamic: map[string]any{
+					"Port": "8080",
+				},
+				Arguments: []string{"--port " + formatPlaceholder("Dynamic.Port")},
+				PreOperations: []Operation{
+					{
+						Enabled:             false,
+						StopIfUnsuccessful:  true,
+						CaptureStdOut:       true,
+						Command:             "/path/to/check-dependencies",
+						SecondsUntilTimeout: 30,
+					},
+				},
+				PostOperations: []Operation{
+					{
+						Enabled:             false,
+						CaptureStdOut:       true,
+						Command:             "echo",
+						SecondsUntilTimeout: 10,
+						Arguments: []string{
+							formatPlaceholder("Name") + " stopped at " + formatPlaceholder("Date"),
+						},
+					},
+				},
+			},
+		},
+	}
+}
@@ -0,0 +1,91 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"testing"
+)
+
+func TestNewConfigFromTemplate(t *testing.T) {
+	for _, template := range TemplateNames() {
+		for _, format := range FormatNames() {
+			t.Run(template+"/"+format, func(t *testing.T) {
+				setupConfigDir(t, nil)
+				path, created, err := NewConfigFromTemplate(template, false, format)
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if !created {
+					t.Fatalf("%s has not been created", path)
+				}
+
+				_, diags, err := Validate()
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				for _, d := range diags {
+					t.Errorf("unexpected diagnostic: %s", d)
+				}
+
+				conf, err := Reload()
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				want := templates[template]()
+				if len(conf.Tasks()) != len(want.Tasks) {
+					t.Errorf("%d tasks loaded, want %d", len(conf.Tasks()), len(want.Tasks))
+				}
+			})
+		}
+	}
+}
+
+func TestNewConfigFromTemplateExisting(t *testing.T) {
+	setupConfigDir(t, nil)
+	path, _, err := NewConfigFromTemplate("minimal", false, FormatYaml)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = os.WriteFile(path, []byte("modified"), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name      string
+		overwrite bool
+		created   bool
+	}{
+		{name: "existing config is kept", overwrite: false, created: false},
+		{name: "existing config is overwritten", overwrite: true, created: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, created, err := NewConfigFromTemplate("backup", tt.overwrite, FormatYaml)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if created != tt.created {
+				t.Errorf("created = %t, want %t", created, tt.created)
+			}
+			b, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if (string(b) == "modified") == tt.created {
+				t.Errorf("content = %q, want it to be overwritten: %t", b, tt.created)
+			}
+		})
+	}
+}
+
+func TestNewConfigFromUnknownTemplate(t *testing.T) {
+	setupConfigDir(t, nil)
+	_, created, err := NewConfigFromTemplate("missing", false, FormatJson)
+	if !errors.Is(err, ErrUnknownTemplate) {
+		t.Errorf("error = %v, want %v", err, ErrUnknownTemplate)
+	}
+	if created {
+		t.Error("a config has been created")
+	}
+}
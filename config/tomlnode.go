@@ -0,0 +1,196 @@
+package config
+
+import (
+	"strconv"
+	"strings"
+)
+
+// addTomlLines adds the line of every key and table of the toml document b to n, the generically decoded tree of b.
+// The values of inline tables and arrays get the line of their key.
+func addTomlLines(b []byte, n *node) {
+	if n == nil {
+		return
+	}
+	n.line = 1
+
+	// tables contains the number of tables defined so far for each array of tables.
+	tables := make(map[*node]int)
+	table := n
+	lines := strings.Split(string(b), "\n")
+	for i := 0; i < len(lines); i++ {
+		line := strings.TrimSpace(lines[i])
+		switch {
+		case line == "" || line[0] == '#':
+		case strings.HasPrefix(line, "[["):
+			end := indexUnquoted(line, "]]")
+			if end < 0 {
+				continue
+			}
+			table = tomlTable(n, splitTomlKey(line[2:end]), tables, true, i+1)
+		case line[0] == '[':
+			end := indexUnquoted(line, "]")
+			if end < 0 {
+				continue
+			}
+			table = tomlTable(n, splitTomlKey(line[1:end]), tables, false, i+1)
+		default:
+			eq := indexUnquoted(line, "=")
+			if eq < 0 {
+				continue
+			}
+			value := table
+			for _, key := range splitTomlKey(line[:eq]) {
+				f := value.field(key)
+				if f == nil {
+					value = nil
+					break
+				}
+				if f.line == 0 {
+					f.line = i + 1
+				}
+				value = f.value
+			}
+			setLines(value, i+1)
+
+			// Arrays, inline tables and strings may span multiple lines.
+			i += tomlValueLines(append([]string{line[eq+1:]}, lines[i+1:]...))
+		}
+	}
+}
+
+// tomlTable returns the table of the given header path inside root and sets the line of its keys.
+// If array is set, the header defines the next table of an array of tables.
+// Nil is returned if the table does not exist.
+func tomlTable(root *node, path []string, tables map[*node]int, array bool, line int) (n *node) {
+	n = root
+	for i, key := range path {
+		f := n.field(key)
+		if f == nil {
+			return nil
+		}
+		if f.line == 0 {
+			f.line = line
+		}
+		n = f.value
+		if n.kind != nodeArray {
+			continue
+		}
+		if array && i == len(path)-1 {
+			tables[n]++
+		}
+		n = n.item(tables[n] - 1)
+		if n == nil {
+			return nil
+		}
+	}
+	if n.line == 0 {
+		n.line = line
+	}
+	return
+}
+
+// setLines sets the line of n and all its children which do not have a line yet.
+func setLines(n *node, line int) {
+	if n == nil {
+		return
+	}
+	if n.line == 0 {
+		n.line = line
+	}
+	for i := range n.fields {
+		if n.fields[i].line == 0 {
+			n.fields[i].line = line
+		}
+		setLines(n.fields[i].value, line)
+	}
+	for _, item := range n.items {
+		setLines(item, line)
+	}
+}
+
+// tomlValueLines returns the number of lines following lines[0] which belong to the value starting in lines[0].
+func tomlValueLines(lines []string) int {
+	depth := 0
+	quote := ""
+	for i, line := range lines {
+		for j := 0; j < len(line); j++ {
+			if quote != "" {
+				switch {
+				case line[j] == '\\' && quote[0] == '"':
+					j++
+				case strings.HasPrefix(line[j:], quote):
+					j += len(quote) - 1
+					quote = ""
+				}
+				continue
+			}
+			switch c := line[j]; {
+			case strings.HasPrefix(line[j:], `"""`) || strings.HasPrefix(line[j:], "'''"):
+				quote = line[j : j+3]
+				j += 2
+			case c == '"' || c == '\'':
+				quote = string(c)
+			case c == '[' || c == '{':
+				depth++
+			case c == ']' || c == '}':
+				depth--
+			case c == '#':
+				j = len(line)
+			}
+		}
+
+		// Single-line strings can not continue on the next line.
+		if len(quote) == 1 {
+			quote = ""
+		}
+		if depth <= 0 && quote == "" {
+			return i
+		}
+	}
+	return len(lines) - 1
+}
+
+// splitTomlKey splits the given dotted key into its parts and removes their quotes.
+func splitTomlKey(key string) (parts []string) {
+	for {
+		i := indexUnquoted(key, ".")
+		if i < 0 {
+			return append(parts, unquoteTomlKey(key))
+		}
+		parts = append(parts, unquoteTomlKey(key[:i]))
+		key = key[i+1:]
+	}
+}
+
+// unquoteTomlKey removes the spaces and quotes surrounding a single key.
+func unquoteTomlKey(key string) string {
+	key = strings.TrimSpace(key)
+	if len(key) > 1 && key[0] == '\'' && key[len(key)-1] == '\'' {
+		return key[1 : len(key)-1]
+	}
+	unquoted, err := strconv.Unquote(key)
+	if err == nil && key[0] == '"' {
+		return unquoted
+	}
+	return key
+}
+
+// indexUnquoted returns the index of the first sep in s which is not inside quotes or -1 if there is none.
+func indexUnquoted(s, sep string) int {
+	var quote byte
+	for i := 0; i < len(s); i++ {
+		switch {
+		case quote != 0:
+			if s[i] == '\\' && quote == '"' {
+				i++
+			} else if s[i] == quote {
+				quote = 0
+			}
+		case s[i] == '"' || s[i] == '\'':
+			quote = s[i]
+		case strings.HasPrefix(s[i:], sep):
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,695 @@
+package config
+
+import (
+	"WrapNGo/parsing"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+)
+
+var (
+	placeholderReg  = regexp.MustCompile(fmt.Sprintf(`%s([A-Za-z][\w.]*(?:\([^%s)]*\))?)%s`, PlaceholderChar, PlaceholderChar, PlaceholderChar))
+	sizeReg         = regexp.MustCompile(`(?i)^\s*\d+[bkmg]b?\s*$`)
+	argsPlaceholder = regexp.MustCompile(`(?i)^Args(\.\d+)?$`)
+	secretNameReg   = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+	// reservedNames contains the names a task can not be run with directly (see SetReservedNames).
+	reservedNames = make(map[string]bool)
+)
+
+// SetReservedNames defines the names which can not be used to run a task directly, e.g. the names of the commands.
+// Validate reports every task with one of these names.
+func SetReservedNames(names ...string) {
+	reservedNames = make(map[string]bool, len(names))
+	for _, name := range names {
+		reservedNames[name] = true
+	}
+}
+
+// IsReservedName returns whether name has been passed to SetReservedNames.
+func IsReservedName(name string) bool {
+	return reservedNames[name]
+}
+
+// The Diagnostic type describes a single problem found inside a config file.
+// Line is 0 if the problem is not bound to a specific line.
+type Diagnostic struct {
+	File     string
+	Line     int
+	Severity string
+	Message  string
+}
+
+func (d Diagnostic) String() string {
+	if d.Line < 1 {
+		return fmt.Sprintf("%s: %s: %s", d.File, d.Severity, d.Message)
+	}
+	return fmt.Sprintf("%s:%d: %s: %s", d.File, d.Line, d.Severity, d.Message)
+}
+
+// The validatedFile type contains a single decoded config file.
+type validatedFile struct {
+	file
+	format  *Format
+	root    *node
+	conf    Config
+	decoded bool
+
+	// extendsErrs contains the errors of the tasks whose Extends could not be resolved.
+	extendsErrs map[int]error
+
+	// dependsErrs contains the errors of the tasks whose DependsOn could not be resolved.
+	dependsErrs map[int]error
+}
+
+// The validator type collects the diagnostics of all files.
+type validator struct {
+	diags    []Diagnostic
+	settings GeneralSettings
+	globals  map[string]string
+	tasks    map[string]string
+
+	// globalValues contains the GlobalDynamic value of each key in globals.
+	globalValues map[string]any
+
+	// profiles contains the profiles of all files, profileSources the file of each of their values (see mergeProfiles).
+	profiles       map[string]Profile
+	profileSources map[string]string
+
+	// profile is the selected profile (see SelectedProfile).
+	profile Profile
+}
+
+// Validate checks every file LoadAll would load without modifying the in-memory config.
+// It returns the checked files and all problems found, ordered by file and line.
+// An error is only returned if the files could not be found or read or an overridden setting is invalid.
+func Validate() (paths []string, diags []Diagnostic, err error) {
+	list, err := files()
+	if err != nil {
+		return
+	}
+
+	// All files need to be decoded first, GeneralSettings and GlobalDynamic are shared between them.
+	v := &validator{
+		globals:        make(map[string]string),
+		tasks:          make(map[string]string),
+		globalValues:   make(map[string]any),
+		profiles:       make(map[string]Profile),
+		profileSources: make(map[string]string),
+	}
+	decoded := make([]*validatedFile, 0, len(list))
+	mainFound := false
+	for _, f := range list {
+		paths = append(paths, f.path)
+		mainFound = mainFound || f.isMain
+		for _, other := range f.otherMains {
+			v.report(other, 0, SeverityWarning, "multiple main configs found, %s is used as main config instead of this file", filepath.Base(f.path))
+		}
+
+		var vf *validatedFile
+		vf, err = v.decode(f)
+		if err != nil {
+			return
+		}
+		if vf != nil {
+			decoded = append(decoded, vf)
+		}
+	}
+	if !mainFound {
+		dir, _ := Dir()
+		v.report(dir, 0, SeverityWarning, "main config could not be found, please ensure one of %s is available", strings.Join(configFileNames(), ", "))
+	}
+	v.selectProfile(paths)
+	v.settings, _, err = applySettings(v.settings, nil)
+	if err != nil {
+		return
+	}
+
+	v.resolve(decoded)
+	for _, vf := range decoded {
+		if vf.decoded {
+			v.checkFile(vf)
+		}
+	}
+	for _, vf := range decoded {
+		if vf.decoded {
+			v.checkProfiles(vf)
+		}
+	}
+
+	order := make(map[string]int, len(paths))
+	for i, p := range paths {
+		order[p] = i
+	}
+	sort.SliceStable(v.diags, func(i, j int) bool {
+		a, b := v.diags[i], v.diags[j]
+		if a.File != b.File {
+			return order[a.File] < order[b.File]
+		}
+		return a.Line < b.Line
+	})
+	return paths, v.diags, nil
+}
+
+// report adds a new diagnostic.
+func (v *validator) report(path string, line int, severity, format string, a ...any) {
+	v.diags = append(v.diags, Diagnostic{
+		File:     path,
+		Line:     line,
+		Severity: severity,
+		Message:  fmt.Sprintf(format, a...),
+	})
+}
+
+// decode parses the given file and checks its structure.
+// The returned file is nil if it is not even syntactically valid.
+func (v *validator) decode(f file) (vf *validatedFile, err error) {
+	b, err := os.ReadFile(f.path)
+	if err != nil {
+		v.report(f.path, 0, SeverityError, "%v", err)
+		return nil, nil
+	}
+
+	format, err := formatOf(f.path)
+	if err != nil {
+		v.report(f.path, 0, SeverityError, "%v", err)
+		return nil, nil
+	}
+	vf = &validatedFile{file: f, format: format, extendsErrs: make(map[int]error), dependsErrs: make(map[int]error)}
+	vf.root, err = format.parse(b)
+	if err != nil {
+		for _, e := range decodeErrors(b, err) {
+			v.report(f.path, e.line, SeverityError, "%s", e.msg)
+		}
+		return nil, nil
+	}
+	v.checkFields(f.path, vf.root, reflect.TypeOf(Config{}), "", format)
+
+	err = vf.conf.LoadInto(f.path)
+	if errors.Is(err, ErrInvalidVersion) {
+		v.report(f.path, vf.root.lineOf(versionKey), SeverityError, "%v", err)
+		return vf, nil
+	}
+	if err != nil {
+		for _, e := range decodeErrors(b, err) {
+			v.report(f.path, e.line, SeverityError, "%s", e.msg)
+		}
+		return vf, nil
+	}
+	vf.decoded = true
+	for _, note := range vf.conf.migrated {
+		v.report(f.path, vf.root.lineOf(versionKey), SeverityWarning, "%s, run \"config migrate\" to update the file", note)
+	}
+
+	if f.isMain {
+		v.settings = vf.conf.GeneralSettings
+		v.checkIncludes(vf)
+	} else {
+		for _, key := range []string{"GeneralSettings", "Include"} {
+			if vf.root.field(key) != nil {
+				v.report(f.path, vf.root.lineOf(key), SeverityWarning, "%s is only read from the main config and ignored here", key)
+			}
+		}
+	}
+
+	globals := vf.root.get("GlobalDynamic")
+	for k, val := range vf.conf.GlobalDynamic {
+		src, ok := v.globals[k]
+		if ok {
+			v.report(f.path, globals.lineOf(k), SeverityWarning, "GlobalDynamic %q is already defined in %s and ignored", k, src)
+			continue
+		}
+		v.globals[k] = f.path
+		v.globalValues[k] = val
+	}
+
+	profiles := vf.root.get("Profiles")
+	mergeProfiles(v.profiles, v.profileSources, f.path, vf.conf.Profiles, func(profile, key, src string) {
+		v.report(f.path, profiles.lineOf(profile), SeverityWarning, "Profile value %q is already defined in %s and ignored", key, src)
+	})
+	return
+}
+
+// selectProfile applies the selected profile to the settings and GlobalDynamic values used for all checks.
+// An unknown profile is reported for the first file, it is not bound to any of them.
+func (v *validator) selectProfile(paths []string) {
+	name := SelectedProfile()
+	if name == "" || len(paths) == 0 {
+		return
+	}
+	p, err := selectProfile(v.profiles, name)
+	if err != nil {
+		v.report(paths[0], 0, SeverityError, "%v", err)
+		return
+	}
+	v.profile = p
+	p.applySettings(&v.settings)
+	for k := range p.GlobalDynamic {
+		v.globals[k] = v.profileSources[name+".GlobalDynamic."+k]
+	}
+}
+
+// checkProfiles checks the profiles defined inside the given file.
+// It needs to run after checkFile has been called for every file, the profiles may refer to the tasks of all files.
+func (v *validator) checkProfiles(vf *validatedFile) {
+	profiles := vf.root.get("Profiles")
+	for _, name := range sortedProfileNames(vf.conf.Profiles) {
+		p := vf.conf.Profiles[name]
+		pn := profiles.get(name)
+		if p.GeneralSettings.DateFormat != "" {
+			v.checkDateFormat(vf.path, pn.get("GeneralSettings").lineOf("DateFormat"), "Profiles."+name+".GeneralSettings.DateFormat", p.GeneralSettings.DateFormat)
+		}
+
+		dn := pn.get("Dynamic")
+		for task := range p.Dynamic {
+			if _, ok := v.tasks[v.settings.taskKey(task)]; !ok {
+				v.report(vf.path, orLine(dn.lineOf(task), pn.lineOf("")), SeverityWarning, "Profiles.%s.Dynamic: task %q is not defined", name, task)
+			}
+		}
+	}
+}
+
+// checkIncludes reports every pattern of the Include list of the main config which does not match any file.
+func (v *validator) checkIncludes(vf *validatedFile) {
+	inc, err := readIncludes(vf.path)
+	if err != nil {
+		v.report(vf.path, vf.root.lineOf("Include"), SeverityError, "%v", err)
+		return
+	}
+
+	n := vf.root.get("Include")
+	for _, pattern := range inc.unmatched {
+		line := n.lineOf("")
+		for _, item := range n.items {
+			if item.value == pattern {
+				line = item.line
+			}
+		}
+		v.report(vf.path, line, SeverityWarning, "Include pattern %q does not match any file", pattern)
+	}
+}
+
+// resolve merges the tasks of all files with their base tasks just like LoadAll does.
+func (v *validator) resolve(decoded []*validatedFile) {
+	type ref struct {
+		vf *validatedFile
+		i  int
+	}
+	tasks := make([]Task, 0)
+	refs := make([]ref, 0)
+	for _, vf := range decoded {
+		for i, t := range vf.conf.Tasks {
+			tasks = append(tasks, t)
+			refs = append(refs, ref{vf: vf, i: i})
+		}
+	}
+
+	// Duplicates are reported by checkDuplicate, only drop them like LoadAll does.
+	policy, _ := v.settings.duplicatePolicy()
+	drop := duplicates(tasks, v.settings, policy, func(_, _ Task) {})
+	kept := make([]ref, 0, len(refs))
+	for i, r := range refs {
+		if !drop[i] {
+			kept = append(kept, r)
+		}
+	}
+
+	for i, r := range refs {
+		if drop[i] {
+			r.vf.conf.Tasks[r.i] = tasks[i].defaults.apply(tasks[i])
+		}
+	}
+
+	unresolved := withoutIndices(tasks, drop)
+	resolved, errs := resolveExtends(unresolved, v.settings)
+	for i, r := range kept {
+		if errs[i] != nil {
+			// Keep the task as dependency, only its Extends is reported.
+			resolved[i] = unresolved[i]
+			r.vf.extendsErrs[r.i] = errs[i]
+			continue
+		}
+		resolved[i] = v.profile.applyTask(resolved[i].defaults.apply(resolved[i]), v.settings)
+		r.vf.conf.Tasks[r.i] = resolved[i]
+	}
+
+	for i, err := range resolveDependencies(resolved, v.settings) {
+		kept[i].vf.dependsErrs[kept[i].i] = err
+	}
+}
+
+// checkFields reports every key of n which does not match a field of t.
+// Yaml keys need to match exactly, json keys are compared case-insensitively.
+// The "$schema" key of JSON files is allowed at the top level of every file.
+func (v *validator) checkFields(path string, n *node, t reflect.Type, name string, format *Format) {
+	if n == nil {
+		return
+	}
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		if n.kind != nodeObject {
+			return
+		}
+		for _, f := range n.fields {
+			if name == "" && f.key == schemaKey {
+				continue
+			}
+			sf, suggestion, ok := lookupField(t, f.key, format)
+			if !ok {
+				msg := fmt.Sprintf("unknown field %q", f.key)
+				if name != "" {
+					msg += " in " + name
+				}
+				if suggestion != "" {
+					msg += fmt.Sprintf(", did you mean %q?", suggestion)
+				}
+				v.report(path, f.line, SeverityError, "%s", msg)
+				continue
+			}
+			v.checkFields(path, f.value, sf.Type, joinFieldPath(name, sf.Name), format)
+		}
+	case reflect.Map:
+		if n.kind != nodeObject {
+			return
+		}
+		for _, f := range n.fields {
+			v.checkFields(path, f.value, t.Elem(), joinFieldPath(name, f.key), format)
+		}
+	case reflect.Slice:
+		if n.kind != nodeArray {
+			return
+		}
+		for i, item := range n.items {
+			v.checkFields(path, item, t.Elem(), fmt.Sprintf("%s[%d]", name, i), format)
+		}
+	}
+}
+
+// lookupField returns the field of t decoded from the given key.
+// If no field matches but a key with a different case exists, its name is returned as suggestion.
+func lookupField(t reflect.Type, key string, format *Format) (sf reflect.StructField, suggestion string, ok bool) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get(format.TagName)
+		fName := strings.Split(tag, ",")[0]
+		if fName == "-" || !f.IsExported() {
+			continue
+		}
+		if fName == "" {
+			fName = f.Name
+		}
+
+		switch {
+		case fName == key:
+			return f, "", true
+		case strings.EqualFold(fName, key):
+			if !format.CaseSensitive {
+				return f, "", true
+			}
+			suggestion = fName
+		}
+	}
+	return
+}
+
+// joinFieldPath joins the name of a field to its parent.
+func joinFieldPath(parent, name string) string {
+	if parent == "" {
+		return name
+	}
+	return parent + "." + name
+}
+
+// checkFile checks the values of all tasks inside the given file.
+func (v *validator) checkFile(vf *validatedFile) {
+	settings := vf.root.get("GeneralSettings")
+	if vf.isMain && v.settings.DateFormat != "" {
+		v.checkDateFormat(vf.path, settings.lineOf("DateFormat"), "GeneralSettings.DateFormat", v.settings.DateFormat)
+	}
+	if vf.conf.Defaults.DateFormat != "" {
+		v.checkDateFormat(vf.path, vf.root.get("Defaults").lineOf("DateFormat"), "Defaults.DateFormat", vf.conf.Defaults.DateFormat)
+	}
+	policy, err := v.settings.duplicatePolicy()
+	if vf.isMain && err != nil {
+		v.report(vf.path, settings.lineOf("DuplicateTaskPolicy"), SeverityError, "%v", err)
+	}
+
+	tasks := vf.root.get("Tasks")
+	for i, t := range vf.conf.Tasks {
+		tn := tasks.item(i)
+		name := fmt.Sprintf("task #%d", i+1)
+		if t.Name == "" {
+			v.report(vf.path, tn.lineOf(""), SeverityError, "%s has no Name", name)
+		} else {
+			name = fmt.Sprintf("task %q", t.Name)
+			v.checkDuplicate(vf.path, tn.lineOf("Name"), policy, t.Name)
+			if !t.Abstract && IsReservedName(t.Name) {
+				v.report(vf.path, tn.lineOf("Name"), SeverityWarning, "%s is named like a command, it can only be run via \"run %s\"", name, t.Name)
+			}
+		}
+
+		if vf.extendsErrs[i] != nil {
+			v.report(vf.path, tn.lineOf("Extends"), SeverityError, "%v", vf.extendsErrs[i])
+			continue
+		}
+
+		// Abstract tasks are checked through the tasks extending them.
+		if t.Abstract {
+			continue
+		}
+		if vf.dependsErrs[i] != nil {
+			v.report(vf.path, tn.lineOf("DependsOn"), SeverityError, "%v", vf.dependsErrs[i])
+		}
+
+		v.checkCommand(vf.path, tn, name, t.Command)
+		v.checkPlaceholders(vf.path, tn.lineOf("Command"), t, t.Command)
+		args := tn.get("Arguments")
+		for j, a := range t.Arguments {
+			v.checkPlaceholders(vf.path, orLine(args.item(j).lineOf(""), tn.line), t, a)
+		}
+		v.checkPlaceholders(vf.path, tn.lineOf("RemovePathAfterJobCompletes"), t, t.RemovePathAfterJobCompletes)
+		env := tn.get("Environment")
+		for _, k := range sortedKeys(t.Environment) {
+			v.checkPlaceholders(vf.path, orLine(env.lineOf(k), tn.line), t, t.Environment[k])
+		}
+
+		cn := tn.get("Compression")
+		if cn == nil {
+			cn = tn
+		}
+		v.checkPlaceholders(vf.path, cn.lineOf("PathToCompress"), t, t.Compression.PathToCompress)
+		limit := t.Compression.InMemoryCompressionLimit
+		if limit != "" && !strings.Contains(limit, PlaceholderChar) && !IsCompressionLimit(limit) {
+			v.report(vf.path, cn.lineOf("InMemoryCompressionLimit"), SeverityError,
+				"%s: invalid InMemoryCompressionLimit %q, expected a number followed by B, KB, MB or GB", name, limit)
+		}
+		v.checkPlaceholders(vf.path, cn.lineOf("InMemoryCompressionLimit"), t, limit)
+
+		v.checkOperations(vf.path, tn, "PreOperations", t, name+" pre-operation", t.PreOperations)
+		v.checkOperations(vf.path, tn, "PostOperations", t, name+" post-operation", t.PostOperations)
+	}
+}
+
+// checkDuplicate reports a task name which has already been defined before.
+func (v *validator) checkDuplicate(path string, line int, policy, name string) {
+	key := v.settings.taskKey(name)
+	prev, ok := v.tasks[key]
+	if !ok {
+		v.tasks[key] = path
+		return
+	}
+
+	switch policy {
+	case DuplicateTaskError:
+		v.report(path, line, SeverityError, "task %q is already defined in %s", name, prev)
+	case DuplicateTaskLastWins:
+		v.report(path, line, SeverityWarning, "task %q overrides the one defined in %s (%s)", name, prev, policy)
+		v.tasks[key] = path
+	case DuplicateTaskFirstWins:
+		v.report(path, line, SeverityWarning, "task %q is ignored, it is already defined in %s (%s)", name, prev, policy)
+	default:
+		v.report(path, line, SeverityWarning, "task %q is already defined in %s, both will be run", name, prev)
+	}
+}
+
+// checkOperations checks the given operations of t.
+// Inherited operations are reported at the line of the task node tn.
+func (v *validator) checkOperations(path string, tn *node, key string, t Task, name string, ops []Operation) {
+	n := tn.get(key)
+	for i, o := range ops {
+		on := n.item(i)
+		if on == nil {
+			on = tn
+		}
+		opName := fmt.Sprintf("%s #%d", name, i+1)
+		if o.SecondsUntilTimeout < 0 {
+			v.report(path, on.lineOf("SecondsUntilTimeout"), SeverityError, "%s: SecondsUntilTimeout must not be negative", opName)
+		}
+		if o.Enabled {
+			v.checkOperationCommand(path, on, opName, t.Command, o.Command)
+		}
+		v.checkPlaceholders(path, on.lineOf("Command"), t, o.Command)
+		args := on.get("Arguments")
+		for j, a := range o.Arguments {
+			v.checkPlaceholders(path, orLine(args.item(j).lineOf(""), on.line), t, a)
+		}
+	}
+}
+
+// checkCommand reports an empty command which falls back to GeneralSettings.GlobalCommand.
+func (v *validator) checkCommand(path string, n *node, name, command string) {
+	if command != "" {
+		return
+	}
+	if v.settings.GlobalCommand == "" {
+		v.report(path, n.lineOf("Command"), SeverityError, "%s: neither Command, Defaults.Command nor GeneralSettings.GlobalCommand is set", name)
+		return
+	}
+	v.report(path, n.lineOf("Command"), SeverityWarning, "%s: Command is empty, falling back to GlobalCommand %q", name, v.settings.GlobalCommand)
+}
+
+// checkOperationCommand reports the command of an operation which is not run.
+// Operations of a task without Command run the GlobalCommand, otherwise their own Command without any fallback.
+func (v *validator) checkOperationCommand(path string, n *node, name, taskCommand, command string) {
+	switch {
+	case taskCommand == "" && command != "":
+		v.report(path, n.lineOf("Command"), SeverityWarning, "%s: Command %q is ignored, the operations of a task without Command run GlobalCommand %q", name, command, v.settings.GlobalCommand)
+	case taskCommand != "" && command == "":
+		v.report(path, n.lineOf("Command"), SeverityError, "%s: Command is empty, the operations of a task with a Command do not fall back to GlobalCommand", name)
+	}
+}
+
+// checkDateFormat reports a date format which does not contain any known token.
+func (v *validator) checkDateFormat(path string, line int, name, format string) {
+	date, err := parsing.ParseDate(time.Now(), format)
+	if err != nil {
+		v.report(path, line, SeverityError, "%s: invalid date format %q: %v", name, format, err)
+		return
+	}
+	unknown := parsing.UnknownDateTokens(format)
+	if len(unknown) > 0 {
+		v.report(path, line, SeverityWarning, "%s: date format %q contains the unsupported token(s) %s which are kept as text, but parts of them may be replaced by the tokens %s",
+			name, format, strings.Join(unknown, ", "), strings.Join(parsing.DateTokens(), ", "))
+	}
+	if date == format {
+		v.report(path, line, SeverityError, "%s: date format %q does not contain any date token (e.g. YYYY, MM, DD, hh, mm, ss)", name, format)
+	}
+}
+
+// checkPlaceholders reports every placeholder inside value which can not be resolved for t.
+func (v *validator) checkPlaceholders(path string, line int, t Task, value string) {
+	for _, match := range placeholderReg.FindAllStringSubmatch(value, -1) {
+		p := match[1]
+		key := ""
+		switch {
+		case strings.EqualFold(p, "Profile"):
+		case strings.EqualFold(p, "Date"):
+			if v.settings.DateFormat == "" && t.DateFormat == "" {
+				v.report(path, line, SeverityError, "placeholder %s requires GeneralSettings.DateFormat or Defaults.DateFormat", match[0])
+			}
+		case len(p) > 5 && strings.EqualFold(p[:5], "Date("):
+			v.checkDateFormat(path, line, match[0], strings.TrimSuffix(p[5:], ")"))
+		case len(p) > 4 && strings.EqualFold(p[:4], "Env("), argsPlaceholder.MatchString(p):
+		case len(p) > 7 && strings.EqualFold(p[:7], "Secret("):
+			if !IsSecretName(strings.TrimSuffix(p[7:], ")")) {
+				v.report(path, line, SeverityError, "placeholder %s: secret names may only contain letters, digits, '_' and '-'", match[0])
+			}
+		case cutPrefix(p, "Dynamic.", &key):
+			val, ok := t.Dynamic[key]
+			if !ok {
+				v.report(path, line, SeverityError, "unresolved placeholder %s, Dynamic %q is not defined", match[0], key)
+			} else if !isScalar(val) {
+				v.report(path, line, SeverityError, "unresolved placeholder %s, Dynamic %q is not a string, number or boolean", match[0], key)
+			}
+		case cutPrefix(p, "GlobalDynamic.", &key):
+			_, ok := v.globals[key]
+			if !ok {
+				v.report(path, line, SeverityError, "unresolved placeholder %s, GlobalDynamic %q is not defined", match[0], key)
+			} else if !isScalar(v.globalValues[key]) {
+				v.report(path, line, SeverityError, "unresolved placeholder %s, GlobalDynamic %q is not a string, number or boolean", match[0], key)
+			}
+		case cutPrefix(p, "Compression.", &key):
+			if _, ok := reflect.TypeOf(CompressionOptions{}).FieldByName(key); !ok {
+				v.report(path, line, SeverityError, "unresolved placeholder %s, Compression has no field %q", match[0], key)
+			}
+		default:
+			if !isTaskField(p) {
+				v.report(path, line, SeverityWarning, "unknown placeholder %s is passed as is", match[0])
+			}
+		}
+	}
+}
+
+// IsSecretName returns whether name can be used inside a %Secret(name)% placeholder.
+// Secret names may only contain letters, digits, '_' and '-', they are used as file names.
+func IsSecretName(name string) bool {
+	return secretNameReg.MatchString(name)
+}
+
+// IsCompressionLimit returns whether v can be used as Compression.InMemoryCompressionLimit,
+// a whole number followed by B, KB, MB or GB.
+func IsCompressionLimit(v string) bool {
+	return sizeReg.MatchString(v)
+}
+
+// sortedKeys returns the keys of m in lexical order.
+func sortedKeys(m map[string]string) (keys []string) {
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return
+}
+
+// orLine returns line or fallback if line is unknown.
+func orLine(line, fallback int) int {
+	if line < 1 {
+		return fallback
+	}
+	return line
+}
+
+// cutPrefix stores the remainder of s in rest if s starts with prefix.
+func cutPrefix(s, prefix string, rest *string) bool {
+	if !strings.HasPrefix(s, prefix) {
+		return false
+	}
+	*rest = s[len(prefix):]
+	return true
+}
+
+// isScalar returns whether the decoded value v can be used by a placeholder.
+// Maps, lists and null values are never replaced.
+func isScalar(v any) bool {
+	switch v.(type) {
+	case nil, map[string]any, []any, []map[string]any, []string:
+		return false
+	}
+	return true
+}
+
+// isTaskField returns whether name is a field of Task, compared case-insensitively.
+func isTaskField(name string) bool {
+	t := reflect.TypeOf(Task{})
+	for i := 0; i < t.NumField(); i++ {
+		if strings.EqualFold(t.Field(i).Name, name) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,280 @@
+package config
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// validateFiles writes the given files into a temporary config directory and validates them.
+func validateFiles(t *testing.T, files map[string]string) (diags []Diagnostic) {
+	t.Helper()
+	setupConfigDir(t, files)
+	_, diags, err := Validate()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return
+}
+
+// findDiagnostic returns the first diagnostic whose message contains msg.
+func findDiagnostic(diags []Diagnostic, msg string) (d Diagnostic, ok bool) {
+	for _, d = range diags {
+		if strings.Contains(d.Message, msg) {
+			return d, true
+		}
+	}
+	return Diagnostic{}, false
+}
+
+func TestValidateReservedNames(t *testing.T) {
+	SetReservedNames("list", "run")
+	t.Cleanup(func() { SetReservedNames() })
+
+	const msg = "is named like a command"
+	tests := []struct {
+		name   string
+		task   string
+		warned bool
+	}{
+		{name: "command", task: "list", warned: true},
+		{name: "other case", task: "List"},
+		{name: "no command", task: "backup"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diags := validateFiles(t, map[string]string{
+				"config.yaml": "Version: 1\nGeneralSettings:\n  GlobalCommand: echo\nTasks:\n  - Name: " + tt.task + "\n",
+			})
+			d, ok := findDiagnostic(diags, msg)
+			if ok != tt.warned {
+				t.Fatalf("diagnostics = %v, warning expected: %t", diags, tt.warned)
+			}
+			if ok && (d.Severity != SeverityWarning || d.Line != 5) {
+				t.Errorf("diagnostic = %v, want a warning at line 5", d)
+			}
+		})
+	}
+}
+
+func TestValidateLines(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+		msg   string
+		file  string
+		line  int
+	}{
+		{
+			name:  "json",
+			files: map[string]string{"config.json": "{\n\t\"Version\": 1,\n\t\"Tasks\": [\n\t\t{\"Name\": \"a\", \"Command\": \"ls\"},\n\t\t{\"Name\": \"b\",\n\t\t \"Comand\": \"ls\"}\n\t]\n}\n"},
+			msg:   `unknown field "Comand" in Tasks[1]`,
+			file:  "config.json",
+			line:  6,
+		},
+		{
+			name:  "yaml",
+			files: map[string]string{"config.yaml": "Version: 1\nTasks:\n  - Name: a\n    Command: ls\n  - Name: b\n    Comand: ls\n"},
+			msg:   `unknown field "Comand" in Tasks[1]`,
+			file:  "config.yaml",
+			line:  6,
+		},
+		{
+			name: "toml array of tables",
+			files: map[string]string{"config.toml": `Version = 1
+
+[[Tasks]]
+Name = "a"
+Command = "ls"
+Arguments = [
+  "[[Tasks]]",
+  """multi
+[[Tasks]]
+line""",
+]
+
+[[Tasks]]
+Name = "b"
+Comand = "ls"
+`},
+			msg:  `unknown field "Comand" in Tasks[1]`,
+			file: "config.toml",
+			line: 15,
+		},
+		{
+			name: "toml sub table",
+			files: map[string]string{"config.toml": `Version = 1
+
+[[Tasks]]
+Name = "a"
+Command = "ls"
+
+[Tasks.Compression]
+InMemoryCompressionLimit = "lots"
+`},
+			msg:  "invalid InMemoryCompressionLimit",
+			file: "config.toml",
+			line: 8,
+		},
+		{
+			name: "toml inline table",
+			files: map[string]string{"config.toml": `Version = 1
+Tasks = [
+  { Name = "a", Command = "ls" },
+  { Name = "b", Command = "ls", Compression = { InMemoryCompressionLimit = "lots" } },
+]
+`},
+			msg:  "invalid InMemoryCompressionLimit",
+			file: "config.toml",
+			line: 2,
+		},
+		{
+			name: "multiple main configs",
+			files: map[string]string{
+				"config.json": `{"Version": 1, "Tasks": [{"Name": "a", "Command": "ls"}]}`,
+				"config.yaml": "Version: 1\n",
+			},
+			msg:  "multiple main configs found, config.json is used",
+			file: "config.yaml",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diags := validateFiles(t, tt.files)
+			d, ok := findDiagnostic(diags, tt.msg)
+			if !ok {
+				t.Fatalf("no diagnostic contains %q: %v", tt.msg, diags)
+			}
+			if filepath.Base(d.File) != tt.file || d.Line != tt.line {
+				t.Errorf("diagnostic = %v, want %s:%d", d, tt.file, tt.line)
+			}
+		})
+	}
+}
+
+func TestValidateDynamicValues(t *testing.T) {
+	diags := validateFiles(t, map[string]string{
+		"config.yaml": `Version: 1
+GeneralSettings:
+  GlobalCommand: echo
+GlobalDynamic:
+  Hosts: [a, b]
+  Port: 5432
+Tasks:
+  - Name: a
+    Arguments: ["%Dynamic.Keep%", "%Dynamic.Verbose%", "%GlobalDynamic.Port%", "%Dynamic.Paths%", "%GlobalDynamic.Hosts%"]
+    Dynamic:
+      Keep: 3
+      Verbose: true
+      Paths:
+        Source: /srv
+`,
+	})
+
+	tests := []struct {
+		value   string
+		invalid bool
+	}{
+		{value: `Dynamic "Keep"`},
+		{value: `Dynamic "Verbose"`},
+		{value: `GlobalDynamic "Port"`},
+		{value: `Dynamic "Paths"`, invalid: true},
+		{value: `GlobalDynamic "Hosts"`, invalid: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			_, found := findDiagnostic(diags, tt.value+" is not a string, number or boolean")
+			if found != tt.invalid {
+				t.Errorf("reported: %t, want %t: %v", found, tt.invalid, diags)
+			}
+		})
+	}
+}
+
+func TestValidateDateFormats(t *testing.T) {
+	tests := []struct {
+		format   string
+		msg      string
+		severity string
+	}{
+		{format: "YYYY-MM-DD_hh-mm-ss"},
+		{format: "YYYYMMDD"},
+		{format: "DDDD, hha"},
+		{format: "YYYY-QQ", msg: "unsupported token(s) QQ", severity: SeverityWarning},
+		{format: "Month MM", msg: "unsupported token(s) Month", severity: SeverityWarning},
+		{format: "backup_YYYY", msg: "unsupported token(s) backup", severity: SeverityWarning},
+		{format: "YYYY-MM-DD_day", msg: "unsupported token(s) day", severity: SeverityWarning},
+		{format: "--", msg: "does not contain any date token", severity: SeverityError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			diags := validateFiles(t, map[string]string{
+				"config.yaml": "Version: 1\nGeneralSettings:\n  GlobalCommand: echo\n  DateFormat: \"" + tt.format + "\"\n",
+			})
+			d, ok := findDiagnostic(diags, "date format")
+			if tt.msg == "" {
+				if ok {
+					t.Errorf("unexpected diagnostic: %v", d)
+				}
+				return
+			}
+			if !ok || !strings.Contains(d.Message, tt.msg) || d.Line != 4 || d.Severity != tt.severity {
+				t.Errorf("diagnostics = %v, want %s %q at line 4", diags, tt.severity, tt.msg)
+			}
+		})
+	}
+}
+
+func TestIsCompressionLimit(t *testing.T) {
+	tests := []struct {
+		limit string
+		valid bool
+	}{
+		{limit: "1GB", valid: true},
+		{limit: "512mb", valid: true},
+		{limit: "100K", valid: true},
+		{limit: " 2048B ", valid: true},
+		{limit: "1 GB"},
+		{limit: "GB"},
+		{limit: "1TB"},
+		{limit: "1.5GB"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.limit, func(t *testing.T) {
+			if IsCompressionLimit(tt.limit) != tt.valid {
+				t.Errorf("IsCompressionLimit(%q) = %t, want %t", tt.limit, !tt.valid, tt.valid)
+			}
+		})
+	}
+}
+
+func TestValidateOperationCommands(t *testing.T) {
+	tests := []struct {
+		name     string
+		task     string
+		msg      string
+		severity string
+	}{
+		{name: "own command", task: "    Command: job\n    PreOperations:\n      - Enabled: true\n        Command: op\n"},
+		{name: "empty command", task: "    Command: job\n    PreOperations:\n      - Enabled: true\n", msg: "do not fall back to GlobalCommand", severity: SeverityError},
+		{name: "ignored command", task: "    PreOperations:\n      - Enabled: true\n        Command: op\n", msg: `Command "op" is ignored`, severity: SeverityWarning},
+		{name: "disabled", task: "    Command: job\n    PreOperations:\n      - Enabled: false\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diags := validateFiles(t, map[string]string{
+				"config.yaml": "Version: 1\nGeneralSettings:\n  GlobalCommand: echo\nTasks:\n  - Name: backup\n" + tt.task,
+			})
+			d, ok := findDiagnostic(diags, "pre-operation #1")
+			if tt.msg == "" {
+				if ok {
+					t.Errorf("unexpected diagnostic: %v", d)
+				}
+				return
+			}
+			if !ok || !strings.Contains(d.Message, tt.msg) || d.Severity != tt.severity {
+				t.Errorf("diagnostics = %v, want %q", diags, tt.msg)
+			}
+		})
+	}
+}
@@ -0,0 +1,211 @@
+package main
+
+import (
+	"WrapNGo/config"
+	"WrapNGo/logger"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+)
+
+func init() {
+	parent := &command{
+		name:    "config",
+		args:    "<subcommand> [flags] [arguments]",
+		summary: "Manage the config files",
+	}
+	parent.completeWords = parent.subcommandNames
+	registerCommand(parent)
+	registerSubcommand("config", &command{
+		name:    "migrate",
+		args:    "[file...]",
+		summary: "Upgrade the config files to the current Version, keeping a backup of each file",
+		setup:   setupConfigMigrate,
+	})
+	registerSubcommand("config", &command{
+		name:    "convert",
+		args:    "-to <format> [file]",
+		summary: "Convert a config file into another format, the original file is kept as backup",
+		setup:   setupConfigConvert,
+	})
+	registerSubcommand("config", &command{
+		name:        "export",
+		summary:     "Write the effective configuration of all files as a single file",
+		needsConfig: true,
+		setup:       setupConfigExport,
+	})
+	registerSubcommand("config", &command{
+		name:    "schema",
+		summary: "Write the JSON Schema of the config files for editors",
+		setup:   setupConfigSchema,
+	})
+}
+
+// setupConfigMigrate registers the flags of the config migrate command.
+// The config is not loaded beforehand, files written for a newer version could not be loaded at all.
+func setupConfigMigrate(fs *flag.FlagSet) func(args []string) error {
+	dryRun := fs.Bool("dry-run", false, "only print the files which would be migrated")
+	return func(args []string) (err error) {
+		paths := args
+		if len(paths) == 0 {
+			paths, err = config.Files()
+			if errors.Is(err, config.ErrNotFound) {
+				return fmt.Errorf("%w: %v, create one with \"%s init\"", ErrInitializing, err, progName())
+			}
+			if err != nil {
+				return fmt.Errorf("%w: %v", ErrInitializing, err)
+			}
+		}
+
+		failed := 0
+		for _, p := range paths {
+			var res config.MigrationResult
+			res, err = config.MigrateFile(p, *dryRun)
+			switch {
+			case err != nil:
+				failed++
+				logger.Errorf("%s: %v\n", p, err)
+				continue
+			case !res.Migrated():
+				logger.Infof("%s: already at version %d\n", p, res.To)
+				continue
+			case *dryRun:
+				logger.Infof("%s: would be migrated from version %d to %d\n", p, res.From, res.To)
+			default:
+				logger.Infof("%s: migrated from version %d to %d, backup: %s\n", p, res.From, res.To, res.Backup)
+			}
+			for _, note := range res.Notes {
+				logger.Warnf("  %s\n", note)
+			}
+		}
+		if failed > 0 {
+			return fmt.Errorf("%w: %d of %d file(s) could not be migrated", ErrInvalidConfig, failed, len(paths))
+		}
+		return nil
+	}
+}
+
+// setupConfigConvert registers the flags of the config convert command.
+// Without a file, the main config is converted.
+func setupConfigConvert(fs *flag.FlagSet) func(args []string) error {
+	to := fs.String("to", "", "format to convert into ("+strings.Join(config.FormatNames(), ", ")+")")
+	output := fs.String("output", "", "file to write, \"-\" for stdout (default: the file with the extension of the format)")
+	force := fs.Bool("force", false, "overwrite an existing output file")
+	return func(args []string) (err error) {
+		if *to == "" {
+			return fmt.Errorf("%w: config convert requires -to", errUsage)
+		}
+		if len(args) > 1 {
+			return fmt.Errorf("%w: config convert takes at most one file", errUsage)
+		}
+
+		var path string
+		if len(args) == 1 {
+			path = args[0]
+		} else {
+			path, err = config.MainFile()
+			if err != nil {
+				return fmt.Errorf("%w: %v", ErrInitializing, err)
+			}
+		}
+
+		if *output == "-" {
+			var b []byte
+			b, err = config.Convert(path, *to)
+			if err == nil {
+				_, err = os.Stdout.Write(b)
+			}
+			return convertError(err)
+		}
+		out := *output
+		if out == "" {
+			out, err = config.ConvertedPath(path, *to)
+			if err != nil {
+				return convertError(err)
+			}
+		}
+		res, err := config.ConvertFile(path, *to, out, *force)
+		if err != nil {
+			return convertError(err)
+		}
+		logger.Infof("%s: converted to %s, backup: %s\n", path, res.Output, res.Backup)
+		for _, note := range res.Notes {
+			logger.Warnf("  %s\n", note)
+		}
+		return
+	}
+}
+
+// setupConfigExport registers the flags of the config export command.
+func setupConfigExport(fs *flag.FlagSet) func(args []string) error {
+	format := fs.String("format", "", "output format ("+strings.Join(config.FormatNames(), ", ")+", default: the format of -output or json)")
+	output := fs.String("output", "", "file to write (default: stdout)")
+	force := fs.Bool("force", false, "overwrite an existing output file")
+	return func(args []string) (err error) {
+		if len(args) > 0 {
+			return fmt.Errorf("%w: config export does not take any arguments", errUsage)
+		}
+
+		f := *format
+		if f == "" && *output != "" && *output != "-" {
+			f, _ = config.FormatOf(*output)
+		}
+		if f == "" {
+			f = config.FormatJson
+		}
+		b, err := config.Export(f)
+		if err != nil {
+			return convertError(err)
+		}
+
+		if *output == "" || *output == "-" {
+			_, err = os.Stdout.Write(b)
+			return
+		}
+		err = config.WriteOutput(*output, b, *force)
+		if err != nil {
+			return convertError(err)
+		}
+		logger.Infof("Effective configuration written to %s\n", *output)
+		return
+	}
+}
+
+// setupConfigSchema registers the flags of the config schema command.
+func setupConfigSchema(fs *flag.FlagSet) func(args []string) error {
+	output := fs.String("output", "", "file to write (default: stdout)")
+	force := fs.Bool("force", false, "overwrite an existing output file")
+	return func(args []string) (err error) {
+		if len(args) > 0 {
+			return fmt.Errorf("%w: config schema does not take any arguments", errUsage)
+		}
+		b, err := config.Schema()
+		if err != nil {
+			return
+		}
+
+		if *output == "" || *output == "-" {
+			_, err = os.Stdout.Write(b)
+			return
+		}
+		err = config.WriteOutput(*output, b, *force)
+		if err != nil {
+			return convertError(err)
+		}
+		logger.Infof("JSON Schema written to %s\n", *output)
+		return
+	}
+}
+
+// convertError turns an unknown format into a usage error and adds a hint to an existing output.
+func convertError(err error) error {
+	switch {
+	case errors.Is(err, config.ErrUnknownFormat):
+		return fmt.Errorf("%w: %v", errUsage, err)
+	case errors.Is(err, config.ErrOutputExists):
+		return fmt.Errorf("%v, use -force to overwrite it", err)
+	}
+	return err
+}
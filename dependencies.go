@@ -0,0 +1,67 @@
+package main
+
+import (
+	"WrapNGo/config"
+	"strings"
+)
+
+// withDependencies returns tasks together with every task they depend on (see config.Task.DependsOn), directly or indirectly.
+// The added tasks are inserted in front of the first task depending on them, a dependency is only added
+// if no task with its name is part of tasks yet.
+// The added tasks are run as configured, overrides given on the command line are not applied to them.
+func withDependencies(conf *config.Snapshot, tasks []config.Task) (all []config.Task) {
+	caseSensitive := conf.GeneralSettings().CaseSensitiveJobNames
+	names := make(map[string]bool, len(tasks))
+	for _, t := range tasks {
+		names[nameKey(t.Name, caseSensitive)] = true
+	}
+
+	var add func(t config.Task)
+	add = func(t config.Task) {
+		for _, dep := range t.DependsOn {
+			key := nameKey(dep, caseSensitive)
+			if names[key] {
+				continue
+			}
+			names[key] = true
+			for _, d := range conf.TasksNamed(dep) {
+				add(d)
+			}
+		}
+		all = append(all, t)
+	}
+	for _, t := range tasks {
+		add(t)
+	}
+	return
+}
+
+// dependencyGraph returns the indices of the tasks each of the given tasks depends on.
+// Dependencies which are not part of tasks are ignored, a dependency matching multiple tasks depends on all of them.
+func dependencyGraph(tasks []config.Task, caseSensitive bool) (deps [][]int) {
+	byName := make(map[string][]int, len(tasks))
+	for i, t := range tasks {
+		key := nameKey(t.Name, caseSensitive)
+		byName[key] = append(byName[key], i)
+	}
+
+	deps = make([][]int, len(tasks))
+	for i, t := range tasks {
+		for _, dep := range t.DependsOn {
+			for _, j := range byName[nameKey(dep, caseSensitive)] {
+				if j != i {
+					deps[i] = append(deps[i], j)
+				}
+			}
+		}
+	}
+	return
+}
+
+// nameKey returns the key used to compare task names, honoring GeneralSettings.CaseSensitiveJobNames.
+func nameKey(name string, caseSensitive bool) string {
+	if caseSensitive {
+		return name
+	}
+	return strings.ToLower(name)
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"WrapNGo/config"
+	"WrapNGo/logger"
+	"fmt"
+	"os"
+)
+
+// dryRunTask walks through the same steps as RunTask but only prints the resolved values.
+// Neither processes are started nor archives are written.
+func dryRunTask(t config.Task, conf *config.Snapshot) (err error) {
+	logger.Infof("%s: [dry-run] Resolving task\n", t.Name)
+
+	// Secrets, the task would fail before anything is started if one of them can not be read.
+	unresolved, err := unresolvedSecrets(t, conf)
+	if err != nil {
+		return
+	}
+	for _, u := range unresolved {
+		logger.Errorf("%s: [dry-run] Secret %v\n", t.Name, u)
+	}
+	err = unresolvedError(t, unresolved)
+	if err != nil {
+		return
+	}
+
+	// Compression.
+	t.Compression.InMemoryCompressionLimit, err = resolveValue(t, conf, t.Compression.InMemoryCompressionLimit)
+	if err != nil {
+		return
+	}
+	t.Compression.PathToCompress, err = resolveValue(t, conf, t.Compression.PathToCompress)
+	if err != nil {
+		return
+	}
+	if t.Compression.PathToCompress != "" {
+		var plan compressionPlan
+		plan, err = planCompression(t.Compression)
+		if err != nil {
+			err = fmt.Errorf("%s: [dry-run] %w: %q: %v", t.Name, ErrCompressionFailed, logger.Masked(t.Compression.PathToCompress), err)
+			if t.StopIfUnsuccessful {
+				return
+			}
+			logger.Warnf("%v, continuing with the remaining steps\n", err)
+			err = nil
+		} else {
+			mode := "in-memory"
+			if !plan.inMemory {
+				mode = "streamed to file"
+			}
+			logger.Infof(
+				"%s: [dry-run] Compression: %q -> %q (%s, limit: %q, overwrite: %t, retain structure: %t)\n",
+				t.Name, logger.Masked(t.Compression.PathToCompress), logger.Masked(plan.output), mode,
+				logger.Masked(t.Compression.InMemoryCompressionLimit), t.Compression.OverwriteCompressed, t.Compression.RetainStructure,
+			)
+			_, statErr := os.Stat(plan.output)
+			if statErr == nil && !t.Compression.OverwriteCompressed {
+				logger.Warnf("%s: [dry-run] Compression would fail: %s: %s\n", t.Name, ErrArchAlreadyExists, plan.output)
+			}
+			t.Compression.PathToCompress = plan.output
+		}
+	}
+
+	// PreOperations.
+	if t.AllowParallelOperationsRun {
+		logger.Infof("%s: [dry-run] %ss would run in parallel to the job\n", t.Name, jobPreOperation)
+	}
+	for i, o := range t.PreOperations {
+		err = printOperation(t, conf, o, jobPreOperation, i+1)
+		if err != nil {
+			return
+		}
+	}
+
+	// Job.
+	cmd, args, err := buildCommand(t, conf, jobCommand(t, conf), t.Arguments)
+	if err != nil {
+		return
+	}
+	logger.Infof("%s: [dry-run] Job: command %q, argv %q, stop if unsuccessful: %t\n", t.Name, logger.Masked(cmd), masked(args), t.StopIfUnsuccessful)
+	if len(t.Environment) > 0 {
+		var env []string
+		env, err = taskEnvironment(t, conf)
+		if err != nil {
+			return
+		}
+		logger.Infof("%s: [dry-run] Environment: %q\n", t.Name, masked(env))
+	}
+
+	removePath, err := resolveValue(t, conf, t.RemovePathAfterJobCompletes)
+	if err != nil {
+		return
+	}
+	if removePath != "" {
+		logger.Infof("%s: [dry-run] RemovePathAfterJobCompletes: %q\n", t.Name, logger.Masked(removePath))
+	}
+
+	// PostOperations.
+	for i, o := range t.PostOperations {
+		err = printOperation(t, conf, o, jobPostOperation, i+1)
+		if err != nil {
+			return
+		}
+	}
+	return
+}
+
+// printOperation prints the resolved values of a single operation.
+func printOperation(t config.Task, conf *config.Snapshot, o config.Operation, oType string, oNum int) (err error) {
+	if !o.Enabled {
+		logger.Infof("%s: [dry-run] %s #%d: disabled\n", t.Name, oType, oNum)
+		return
+	}
+
+	timeout := "none"
+	if o.SecondsUntilTimeout > 0 && !o.IgnoreTimeout {
+		timeout = fmt.Sprintf("%ds", o.SecondsUntilTimeout)
+	}
+	cmd, args, err := buildCommand(t, conf, operationCommand(t, conf, o), o.Arguments)
+	if err != nil {
+		return
+	}
+	logger.Infof(
+		"%s: [dry-run] %s #%d: command %q, argv %q, timeout: %s, capture stdout: %t, stop if unsuccessful: %t\n",
+		t.Name, oType, oNum, logger.Masked(cmd), masked(args), timeout, o.CaptureStdOut, o.StopIfUnsuccessful,
+	)
+	if cmd == "" {
+		logger.Warnf("%s: [dry-run] %s #%d would fail: the Command is empty\n", t.Name, oType, oNum)
+	}
+	return
+}
+
+// masked returns a copy of values with every secret masked.
+// Values are quoted by %q before the logger masks them, the escaping would keep secrets containing quotes or backslashes visible.
+func masked(values []string) []string {
+	m := make([]string, len(values))
+	for i, v := range values {
+		m[i] = logger.Masked(v)
+	}
+	return m
+}
@@ -1,10 +1,17 @@
 package main
 
-const (
-	ErrInitializing      = "error while initializing"
-	ErrUserInterrupt     = "interrupt received"
-	ErrOperationFailed   = "operation failed"
-	ErrJobFailed         = "job failed"
-	ErrTimeout           = "timeout reached"
-	ErrArchAlreadyExists = "archive already exists"
+import "errors"
+
+var (
+	ErrInitializing      = errors.New("error while initializing")
+	ErrUserInterrupt     = errors.New("interrupt received")
+	ErrOperationFailed   = errors.New("operation failed")
+	ErrJobFailed         = errors.New("job failed")
+	ErrTimeout           = errors.New("timeout reached")
+	ErrArchAlreadyExists = errors.New("archive already exists")
+	ErrCompressionFailed = errors.New("compression failed")
+	ErrTaskNotFound      = errors.New("no such task found")
+	ErrTasksFailed       = errors.New("one or more tasks failed")
+	ErrInvalidConfig     = errors.New("invalid configuration")
+	ErrConfigExists      = errors.New("config already exists")
 )
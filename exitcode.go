@@ -0,0 +1,82 @@
+package main
+
+import (
+	"errors"
+	"flag"
+)
+
+// Exit codes of the executable.
+// If multiple tasks failed, the code of the highest precedence (see exitPrecedence) is used.
+const (
+	exitOK                = 0
+	exitFailure           = 1
+	exitUsage             = 2
+	exitConfig            = 3
+	exitTaskNotFound      = 4
+	exitJobFailed         = 5
+	exitOperationFailed   = 6
+	exitTimeout           = 7
+	exitCompressionFailed = 8
+	exitInterrupted       = 130
+)
+
+// exitPrecedence orders the exit codes from the highest to the lowest precedence.
+var exitPrecedence = []int{
+	exitInterrupted,
+	exitUsage,
+	exitConfig,
+	exitTaskNotFound,
+	exitTimeout,
+	exitOperationFailed,
+	exitCompressionFailed,
+	exitJobFailed,
+	exitFailure,
+}
+
+// exitCode derives the exit code from the given error.
+func exitCode(err error) int {
+	if err == nil {
+		return exitOK
+	}
+
+	var rErr *runError
+	if errors.As(err, &rErr) {
+		return rErr.exitCode()
+	}
+
+	switch {
+	case errors.Is(err, ErrUserInterrupt):
+		return exitInterrupted
+	case errors.Is(err, errUsage), errors.Is(err, flag.ErrHelp):
+		return exitUsage
+	case errors.Is(err, ErrInitializing), errors.Is(err, ErrInvalidConfig):
+		return exitConfig
+	case errors.Is(err, ErrTaskNotFound):
+		return exitTaskNotFound
+	case errors.Is(err, ErrTimeout):
+		return exitTimeout
+	case errors.Is(err, ErrOperationFailed):
+		return exitOperationFailed
+	case errors.Is(err, ErrCompressionFailed):
+		return exitCompressionFailed
+	case errors.Is(err, ErrJobFailed):
+		return exitJobFailed
+	}
+	return exitFailure
+}
+
+// precedingExitCode returns the exit code with the higher precedence.
+func precedingExitCode(a, b int) int {
+	if a == exitOK {
+		return b
+	}
+	if b == exitOK {
+		return a
+	}
+	for _, c := range exitPrecedence {
+		if c == a || c == b {
+			return c
+		}
+	}
+	return a
+}
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"WrapNGo/config"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	formatTable = "table"
+	formatJson  = "json"
+	formatYaml  = "yaml"
+)
+
+// The taskListing type is the machine-readable representation of a task used by the list command.
+type taskListing struct {
+	Name           string                     `json:"Name" yaml:"Name"`
+	Source         string                     `json:"Source" yaml:"Source"`
+	Command        string                     `json:"Command" yaml:"Command"`
+	Tags           []string                   `json:"Tags" yaml:"Tags"`
+	DependsOn      []string                   `json:"DependsOn" yaml:"DependsOn"`
+	PreOperations  []operationListing         `json:"PreOperations" yaml:"PreOperations"`
+	PostOperations []operationListing         `json:"PostOperations" yaml:"PostOperations"`
+	Compression    *config.CompressionOptions `json:"Compression" yaml:"Compression"`
+}
+
+// The operationListing type describes an enabled operation of a task.
+// Number is the position of the operation inside the config, starting at 1.
+type operationListing struct {
+	Number  int    `json:"Number" yaml:"Number"`
+	Command string `json:"Command" yaml:"Command"`
+}
+
+// newTaskListing creates the listing of t, the commands are chosen like RunTask does from conf.
+func newTaskListing(t config.Task, conf *config.Snapshot) (l taskListing) {
+	l = taskListing{
+		Name:           t.Name,
+		Source:         t.Source,
+		Command:        jobCommand(t, conf),
+		Tags:           make([]string, 0),
+		DependsOn:      make([]string, 0),
+		PreOperations:  enabledOperations(t, conf, t.PreOperations),
+		PostOperations: enabledOperations(t, conf, t.PostOperations),
+	}
+	l.Tags = append(l.Tags, t.Tags...)
+	l.DependsOn = append(l.DependsOn, t.DependsOn...)
+	if t.Compression.PathToCompress != "" {
+		c := t.Compression
+		l.Compression = &c
+	}
+	return
+}
+
+// enabledOperations returns the listing of every enabled operation of t.
+func enabledOperations(t config.Task, conf *config.Snapshot, ops []config.Operation) (listed []operationListing) {
+	listed = make([]operationListing, 0)
+	for i, o := range ops {
+		if !o.Enabled {
+			continue
+		}
+		listed = append(listed, operationListing{Number: i + 1, Command: operationCommand(t, conf, o)})
+	}
+	return
+}
+
+// writeTaskListing writes the given tasks in the desired format to w.
+func writeTaskListing(w io.Writer, conf *config.Snapshot, tasks []config.Task, format string) (err error) {
+	listed := make([]taskListing, len(tasks))
+	for i, t := range tasks {
+		listed[i] = newTaskListing(t, conf)
+	}
+
+	isTable, err := writeEncoded(w, listed, format)
+	if err != nil || !isTable {
+		return
+	}
+
+	dir, _ := config.Dir()
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "NAME\tSOURCE\tCOMMAND\tTAGS\tPRE\tPOST\tCOMPRESSION")
+	for i, l := range listed {
+		src := l.Source
+		rel, relErr := filepath.Rel(dir, src)
+		if relErr == nil && !strings.HasPrefix(rel, "..") {
+			src = rel
+		}
+
+		compression := "-"
+		if l.Compression != nil {
+			compression = l.Compression.PathToCompress
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%d/%d\t%d/%d\t%s\n",
+			l.Name, src, l.Command, strings.Join(l.Tags, ","),
+			len(l.PreOperations), len(tasks[i].PreOperations),
+			len(l.PostOperations), len(tasks[i].PostOperations),
+			compression,
+		)
+	}
+	return tw.Flush()
+}
+
+// writeSettingListing writes the given settings in the desired format to w.
+func writeSettingListing(w io.Writer, settings []config.Setting, format string) (err error) {
+	isTable, err := writeEncoded(w, settings, format)
+	if err != nil || !isTable {
+		return
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "SETTING\tVALUE\tORIGIN")
+	for _, s := range settings {
+		origin := s.Origin
+		if s.Source != "" {
+			origin += " (" + s.Source + ")"
+		}
+		fmt.Fprintf(tw, "%s\t%v\t%s\n", s.Name, s.Value, origin)
+	}
+	return tw.Flush()
+}
+
+// writeEncoded writes v to w if format is json or yaml.
+// isTable is true if format is table and v still needs to be written by the caller.
+func writeEncoded(w io.Writer, v any, format string) (isTable bool, err error) {
+	switch format {
+	case formatJson:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return false, enc.Encode(v)
+	case formatYaml:
+		enc := yaml.NewEncoder(w)
+		enc.SetIndent(2)
+		err = enc.Encode(v)
+		if err != nil {
+			return
+		}
+		return false, enc.Close()
+	case formatTable:
+		return true, nil
+	}
+	return false, fmt.Errorf("%w: unknown format %q", errUsage, format)
+}
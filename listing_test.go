@@ -0,0 +1,69 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNewTaskListing(t *testing.T) {
+	conf := reloadFiles(t, map[string]string{
+		"config.yaml": `Version: 1
+GeneralSettings:
+  GlobalCommand: global
+Tasks:
+  - Name: own
+    Command: job
+    PreOperations:
+      - Command: op
+        Enabled: true
+      - Arguments: [a]
+        Enabled: true
+      - Command: disabled
+    PostOperations:
+      - Command: post
+        Enabled: true
+  - Name: none
+    PreOperations:
+      - Command: op
+        Enabled: true
+`,
+	})
+
+	tests := []struct {
+		task string
+		cmd  string
+		pre  []operationListing
+		post []operationListing
+	}{
+		{
+			task: "own",
+			cmd:  "job",
+			pre:  []operationListing{{Number: 1, Command: "op"}, {Number: 2, Command: ""}},
+			post: []operationListing{{Number: 1, Command: "post"}},
+		},
+		{
+			task: "none",
+			cmd:  "global",
+			pre:  []operationListing{{Number: 1, Command: "global"}},
+			post: []operationListing{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.task, func(t *testing.T) {
+			task, ok := conf.Task(tt.task)
+			if !ok {
+				t.Fatalf("task %q not found", tt.task)
+			}
+			l := newTaskListing(task, conf)
+			if l.Command != tt.cmd {
+				t.Errorf("Command = %q, want %q", l.Command, tt.cmd)
+			}
+			if !reflect.DeepEqual(l.PreOperations, tt.pre) {
+				t.Errorf("PreOperations = %v, want %v", l.PreOperations, tt.pre)
+			}
+			if !reflect.DeepEqual(l.PostOperations, tt.post) {
+				t.Errorf("PostOperations = %v, want %v", l.PostOperations, tt.post)
+			}
+		})
+	}
+}
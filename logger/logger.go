@@ -5,6 +5,8 @@ import (
 	"io"
 	"log"
 	"os"
+	"sort"
+	"strings"
 	"sync"
 	"time"
 )
@@ -19,12 +21,17 @@ const (
 	colorCyan   = "\033[36m"
 	colorGray   = "\033[37m"
 	logFormat   = "2006/01/02 15:04:05"
+	maskValue   = "***"
 )
 
 var (
 	l  *logger
 	ow *operationWriter
 	jw *jobWriter
+
+	maskMux  sync.RWMutex
+	masked   = make(map[string]bool)
+	replacer = strings.NewReplacer()
 )
 
 type logWriter struct {
@@ -33,7 +40,68 @@ type logWriter struct {
 }
 
 func (w logWriter) Write(b []byte) (n int, err error) {
-	return w.Writer.Write(append([]byte(time.Now().Format(w.format)), b...))
+	maskMux.RLock()
+	msg := replacer.Replace(string(b))
+	maskMux.RUnlock()
+	return w.Writer.Write(append([]byte(time.Now().Format(w.format)), msg...))
+}
+
+// Mask replaces every occurrence of the given values with "***" in everything printed afterwards.
+// It is used to hide secrets, empty values are ignored.
+func Mask(values ...string) {
+	maskMux.Lock()
+	defer maskMux.Unlock()
+	for _, v := range values {
+		if v != "" {
+			masked[v] = true
+		}
+	}
+
+	// Longer values need to be replaced first, they may contain shorter ones.
+	keys := make([]string, 0, len(masked))
+	for v := range masked {
+		keys = append(keys, v)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if len(keys[i]) != len(keys[j]) {
+			return len(keys[i]) > len(keys[j])
+		}
+		return keys[i] < keys[j]
+	})
+	pairs := make([]string, 0, 2*len(keys))
+	for _, v := range keys {
+		pairs = append(pairs, v, maskValue)
+	}
+	replacer = strings.NewReplacer(pairs...)
+}
+
+// Masked returns v with every value given to Mask replaced by "***".
+// Values need to be masked before they are quoted or escaped, otherwise the printed text does not contain them anymore.
+func Masked(v string) string {
+	maskMux.RLock()
+	defer maskMux.RUnlock()
+	return replacer.Replace(v)
+}
+
+type maskWriter struct {
+	io.Writer
+}
+
+func (w maskWriter) Write(b []byte) (n int, err error) {
+	maskMux.RLock()
+	msg := replacer.Replace(string(b))
+	maskMux.RUnlock()
+	_, err = io.WriteString(w.Writer, msg)
+	if err != nil {
+		return
+	}
+	return len(b), nil
+}
+
+// MaskWriter returns a writer which masks every value given to Mask before writing to w.
+// Values are only masked if they are contained in a single write.
+func MaskWriter(w io.Writer) io.Writer {
+	return maskWriter{Writer: w}
 }
 
 type operationWriter struct {
@@ -182,5 +250,5 @@ func Fatal(v ...any) {
 }
 
 func Fatalf(format string, v ...any) {
-	l.error.Fatalf(format, v)
+	l.error.Fatalf(format, v...)
 }
@@ -5,107 +5,53 @@ import (
 	"WrapNGo/logger"
 	"errors"
 	"fmt"
-	"log"
 	"os"
 	"strings"
-	"sync"
 
 	"github.com/AlecAivazis/survey/v2"
 )
 
-func init() {
-	// Create new config if not already existing.
-	created := createConf(false, false)
-	if created {
-		os.Exit(0)
-	}
+func main() {
+	os.Exit(execute(os.Args[1:]))
+}
 
+// prepare loads the config and sets up the logger.
+func prepare() (err error) {
 	// Load config values.
-	err := config.LoadAll()
+	err = config.LoadAll()
+	if errors.Is(err, config.ErrNotFound) {
+		return fmt.Errorf("%w: %v, create one with \"%s init\"", ErrInitializing, err, progName())
+	}
 	if err != nil {
-		log.Fatalf("%s: %s", ErrInitializing, err)
-		return
+		return fmt.Errorf("%w: %v", ErrInitializing, err)
 	}
 
 	// Create a new logger.
-	logger.NewInstance(config.Current().GeneralSettings.Debug)
-}
-
-func main() {
-	args := os.Args
-	if len(args) < 2 {
-		interactive()
-		return
-	}
-
-	numErr := 0
-	if len(args) > 1 {
-		conf := config.Current()
-		tasks := make([]config.Task, 0)
-		if conf.GeneralSettings.CaseSensitiveJobNames {
-			for _, t := range conf.Tasks {
-				if t.Name != args[1] {
-					continue
-				}
-				tasks = append(tasks, t)
-			}
-		} else {
-			for _, t := range conf.Tasks {
-				if strings.ToLower(t.Name) != strings.ToLower(args[1]) {
-					continue
-				}
-				tasks = append(tasks, t)
-			}
-		}
-
-		if len(tasks) < 1 {
-			logger.Warn("no such task found.")
-			return
-		}
-
-		// Run each task in a separate goroutine to parallelize.
-		wg := sync.WaitGroup{}
-		for _, t := range tasks {
-			wg.Add(1)
-			logger.Infof("Starting Task \"%s\" in the background.\n", t.Name)
-			go func(t config.Task) {
-				defer wg.Done()
-				err := RunTask(t, conf.GlobalDynamic)
-				if err != nil {
-					logger.Error(err)
-					numErr++
-				}
-				logger.Infof("%s: Task finished\n", t.Name)
-			}(t)
-		}
-		wg.Wait()
-		if numErr > 0 {
-			os.Exit(1)
-		}
-		return
-	}
-	logger.Info("Please provide a command or task name.")
+	logger.NewInstance(config.CurrentSnapshot().GeneralSettings().Debug)
+	return
 }
 
 func interactive() {
 	const (
 		listTasks   = "List tasks"
 		executeTask = "Execute tasks"
+		createTsk   = "Create task"
 		createJson  = "Create main json config (config.json)"
 		createYaml  = "Create main yaml config (config.yaml)"
 		regen       = "Regenerate main configs"
+		reload      = "Reload configs"
 		exit        = "Exit"
 	)
 
-	conf := config.Current()
 	for {
-		jPath, err := config.FullPath(false)
+		conf := config.CurrentSnapshot()
+		jPath, err := config.FullPath(config.FormatJson)
 		if err != nil {
 			logger.Error(err)
 			continue
 		}
 
-		yPath, err := config.FullPath(true)
+		yPath, err := config.FullPath(config.FormatYaml)
 		if err != nil {
 			logger.Error(err)
 			continue
@@ -114,6 +60,7 @@ func interactive() {
 		opts := []string{
 			listTasks,
 			executeTask,
+			createTsk,
 		}
 
 		// Append options dynamically.
@@ -125,7 +72,7 @@ func interactive() {
 		if errors.Is(err, os.ErrNotExist) {
 			opts = append(opts, createYaml)
 		}
-		opts = append(opts, regen, exit)
+		opts = append(opts, regen, reload, exit)
 
 		opt := ""
 		err = survey.AskOne(&survey.Select{
@@ -140,21 +87,43 @@ func interactive() {
 		switch opt {
 		case listTasks:
 			// List all tasks.
+			tasks := conf.Tasks()
 			tskStr := "tasks are"
-			if len(conf.Tasks) == 1 {
+			if len(tasks) == 1 {
 				tskStr = "task is"
 			}
 
-			logger.Infof("Currently %d %s stored:\n", len(conf.Tasks), tskStr)
-			for i := 0; i < len(conf.Tasks); i++ {
-				logger.Infof("\t> %s: %s", conf.Tasks[i].Name, conf.Tasks[i].Command)
+			logger.Infof("Currently %d %s stored:\n", len(tasks), tskStr)
+			err = writeTaskListing(os.Stdout, conf, tasks, formatTable)
+			if err != nil {
+				logger.Error(err)
 			}
 		case executeTask:
-			// Execute selected task.
+			// Filter the tasks and execute the selected one.
+			filter := ""
+			err = survey.AskOne(&survey.Input{
+				Message: "Filter tasks (name, glob, re:<regex> or tag:<tag>, empty for all)",
+			}, &filter)
+			if err != nil {
+				logger.Fatal(ErrUserInterrupt)
+			}
+
+			matched := conf.Tasks()
+			if strings.TrimSpace(filter) != "" {
+				matched, err = findTasks(conf, []string{strings.TrimSpace(filter)})
+				if err != nil {
+					logger.Error(err)
+					continue
+				}
+			}
+			if len(matched) < 1 {
+				continue
+			}
+
 			ind := 0
-			tasks := make([]string, len(conf.Tasks))
-			for i := 0; i < len(conf.Tasks); i++ {
-				tasks[i] = conf.Tasks[i].Name
+			tasks := make([]string, len(matched))
+			for i := 0; i < len(matched); i++ {
+				tasks[i] = matched[i].Name
 			}
 
 			err = survey.AskOne(&survey.Select{
@@ -165,12 +134,24 @@ func interactive() {
 				logger.Fatal(err)
 			}
 
-			os.Args = []string{os.Args[0], conf.Tasks[ind].Name}
-			main()
+			_, err = runTasks(withDependencies(conf, []config.Task{matched[ind]}), conf, runOptions{})
+			if err != nil {
+				logger.Error(err)
+			}
+		case createTsk:
+			// Ask for a new task and append it to a config file.
+			err = createTask(conf)
+			if errors.Is(err, ErrUserInterrupt) {
+				logger.Info("Task creation cancelled")
+				continue
+			}
+			if err != nil {
+				logger.Error(err)
+			}
 		case createJson:
-			createConf(false, false)
+			createConf(false, config.FormatJson)
 		case createYaml:
-			createConf(false, true)
+			createConf(false, config.FormatYaml)
 		case regen:
 			// Regenerate config.
 			confirm := false
@@ -183,8 +164,16 @@ func interactive() {
 			if !confirm {
 				continue
 			}
-			createConf(true, false)
-			createConf(true, true)
+			createConf(true, config.FormatJson)
+			createConf(true, config.FormatYaml)
+		case reload:
+			// Reload every config, the current one is kept if loading fails.
+			conf, err = config.Reload()
+			if err != nil {
+				logger.Error(err)
+				continue
+			}
+			logger.Infof("Reloaded the configs, %d task(s) loaded\n", len(conf.Tasks()))
 		case exit:
 			// Exit.
 			return
@@ -194,13 +183,14 @@ func interactive() {
 }
 
 // createConf is a small convenience wrapper to create a new config in the desired format.
-func createConf(overwrite, isYaml bool) (created bool) {
-	path, created, err := config.NewConfig(overwrite, isYaml)
+func createConf(overwrite bool, format string) (created bool) {
+	path, created, err := config.NewConfig(overwrite, format)
 	if err != nil {
-		log.Fatal(err)
+		logger.Error(err)
+		return
 	}
 	if created {
-		log.Printf("Please modify the created config and restart. Path of config: %s\n", path)
+		logger.Infof("Please modify the created config and restart or reload the configs. Path of config: %s\n", path)
 	}
 	return
 }
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"WrapNGo/config"
+	"WrapNGo/logger"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestMain(m *testing.M) {
+	logger.NewInstance(false)
+	os.Exit(m.Run())
+}
+
+// The fixtureOption type changes the selection of setupConfigDir.
+type fixtureOption func(t *testing.T)
+
+// withProfile selects the given profile like the -profile flag.
+func withProfile(name string) fixtureOption {
+	return func(t *testing.T) {
+		config.SetProfile(name)
+		t.Cleanup(func() { config.SetProfile("") })
+	}
+}
+
+// setupConfigDir writes the given files into a new temporary config directory and selects it.
+// Names may contain subdirectories. Everything selected is reset when the test ends.
+func setupConfigDir(t *testing.T, files map[string]string, opts ...fixtureOption) (dir string) {
+	t.Helper()
+	dir = t.TempDir()
+	t.Setenv(config.EnvConfigDir, dir)
+	t.Setenv(config.EnvConfigFile, "")
+	t.Setenv(config.EnvProfile, "")
+	for _, opt := range opts {
+		opt(t)
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		err := os.MkdirAll(filepath.Dir(path), 0700)
+		if err == nil {
+			err = os.WriteFile(path, []byte(content), 0600)
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	return
+}
+
+// reloadFiles writes the given files like setupConfigDir and loads them, the test fails if they can not be loaded.
+func reloadFiles(t *testing.T, files map[string]string, opts ...fixtureOption) *config.Snapshot {
+	t.Helper()
+	setupConfigDir(t, files, opts...)
+	conf, err := config.Reload()
+	if err != nil {
+		t.Fatalf("unable to load the configs: %v", err)
+	}
+	return conf
+}
+
+// captureOutput returns everything written to stdout and stderr while f runs, including the output of the logger.
+func captureOutput(t *testing.T, f func()) string {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout, stderr := os.Stdout, os.Stderr
+	os.Stdout, os.Stderr = w, w
+	logger.NewInstance(false)
+	defer func() {
+		os.Stdout, os.Stderr = stdout, stderr
+		logger.NewInstance(false)
+	}()
+
+	out := make(chan string)
+	go func() {
+		b, _ := io.ReadAll(r)
+		out <- string(b)
+	}()
+	f()
+	w.Close()
+	return <-out
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"WrapNGo/config"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+const (
+	overrideDynamic       = "Dynamic."
+	overrideGlobalDynamic = "GlobalDynamic."
+)
+
+var (
+	argsPlaceholder = fmt.Sprintf("%sArgs%s", config.PlaceholderChar, config.PlaceholderChar)
+	argsReg         = regexp.MustCompile(fmt.Sprintf("(?i)%sArgs(?:\\.(\\d+))?%s", config.PlaceholderChar, config.PlaceholderChar))
+)
+
+// The runOverrides type contains the values given on the command line which override the config.
+type runOverrides struct {
+	dynamic       map[string]any
+	globalDynamic map[string]any
+	extra         []string
+}
+
+// parseOverrides parses the given "Key=Value" pairs.
+// Keys have to start with either "Dynamic." or "GlobalDynamic.".
+func parseOverrides(sets []string, extra []string) (o runOverrides, err error) {
+	o = runOverrides{
+		dynamic:       make(map[string]any),
+		globalDynamic: make(map[string]any),
+		extra:         extra,
+	}
+	for _, set := range sets {
+		key, value, ok := strings.Cut(set, "=")
+		if !ok {
+			return o, fmt.Errorf("%w: -set %q is not in the format Key=Value", errUsage, set)
+		}
+
+		switch {
+		case strings.HasPrefix(key, overrideDynamic) && len(key) > len(overrideDynamic):
+			o.dynamic[strings.TrimPrefix(key, overrideDynamic)] = value
+		case strings.HasPrefix(key, overrideGlobalDynamic) && len(key) > len(overrideGlobalDynamic):
+			o.globalDynamic[strings.TrimPrefix(key, overrideGlobalDynamic)] = value
+		default:
+			return o, fmt.Errorf("%w: -set key %q has to start with %q or %q", errUsage, key, overrideDynamic, overrideGlobalDynamic)
+		}
+	}
+	return
+}
+
+// applyGlobal returns a snapshot derived from conf whose GlobalDynamic contains the overridden values.
+func (o runOverrides) applyGlobal(conf *config.Snapshot) *config.Snapshot {
+	if len(o.globalDynamic) == 0 {
+		return conf
+	}
+	return conf.WithGlobalDynamic(o.globalDynamic)
+}
+
+// applyTask returns a copy of t containing the overridden values and the extra arguments.
+// The extra arguments are appended to the job's arguments unless the job uses the %Args% placeholders.
+func (o runOverrides) applyTask(t config.Task) config.Task {
+	dyn := make(map[string]any, len(t.Dynamic)+len(o.dynamic))
+	for k, v := range t.Dynamic {
+		dyn[k] = v
+	}
+	for k, v := range o.dynamic {
+		dyn[k] = v
+	}
+	t.Dynamic = dyn
+	t.Args = o.extra
+	if len(o.extra) < 1 || usesArgs(t) {
+		return t
+	}
+
+	args := make([]string, 0, len(t.Arguments)+1)
+	args = append(args, t.Arguments...)
+	t.Arguments = append(args, argsPlaceholder)
+	return t
+}
+
+// usesArgs returns whether the job of t contains an %Args% placeholder.
+func usesArgs(t config.Task) bool {
+	if argsReg.MatchString(t.Command) {
+		return true
+	}
+	for _, a := range t.Arguments {
+		if argsReg.MatchString(a) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,179 @@
+package main
+
+import (
+	"WrapNGo/config"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestParseOverrides(t *testing.T) {
+	tests := []struct {
+		name          string
+		sets          []string
+		dynamic       map[string]any
+		globalDynamic map[string]any
+		err           bool
+	}{
+		{
+			name:          "dynamic and global dynamic",
+			sets:          []string{"Dynamic.Destination=/mnt/usb", "GlobalDynamic.Host=staging"},
+			dynamic:       map[string]any{"Destination": "/mnt/usb"},
+			globalDynamic: map[string]any{"Host": "staging"},
+		},
+		{
+			name:          "value containing equal signs",
+			sets:          []string{"Dynamic.Query=a=b"},
+			dynamic:       map[string]any{"Query": "a=b"},
+			globalDynamic: map[string]any{},
+		},
+		{
+			name:          "last value wins",
+			sets:          []string{"Dynamic.A=1", "Dynamic.A=2"},
+			dynamic:       map[string]any{"A": "2"},
+			globalDynamic: map[string]any{},
+		},
+		{name: "missing value", sets: []string{"Dynamic.A"}, err: true},
+		{name: "unknown prefix", sets: []string{"Environment.A=1"}, err: true},
+		{name: "missing name", sets: []string{"Dynamic.=1"}, err: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o, err := parseOverrides(tt.sets, nil)
+			if tt.err {
+				if !errors.Is(err, errUsage) {
+					t.Errorf("error = %v, want %v", err, errUsage)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(o.dynamic, tt.dynamic) {
+				t.Errorf("dynamic = %v, want %v", o.dynamic, tt.dynamic)
+			}
+			if !reflect.DeepEqual(o.globalDynamic, tt.globalDynamic) {
+				t.Errorf("globalDynamic = %v, want %v", o.globalDynamic, tt.globalDynamic)
+			}
+		})
+	}
+}
+
+func TestApplyOverrides(t *testing.T) {
+	tests := []struct {
+		name      string
+		task      config.Task
+		sets      []string
+		extra     []string
+		wantCmd   string
+		wantArgs  []string
+		wantValue string
+	}{
+		{
+			name:     "extra arguments are appended",
+			task:     config.Task{Command: "cmd", Arguments: []string{"-v"}},
+			extra:    []string{"a", "b"},
+			wantCmd:  "cmd",
+			wantArgs: []string{"-v", "a", "b"},
+		},
+		{
+			name:     "extra arguments keep their spaces",
+			task:     config.Task{Command: "cmd"},
+			extra:    []string{"some value", "trailing\\"},
+			wantCmd:  "cmd",
+			wantArgs: []string{"some value", "trailing\\"},
+		},
+		{
+			name:     "placeholders inside extra arguments are not replaced",
+			task:     config.Task{Command: "cmd", Dynamic: map[string]any{"A": "x"}},
+			extra:    []string{"%Dynamic.A%", "%Args%", "%Secret(A)%"},
+			wantCmd:  "cmd",
+			wantArgs: []string{"%Dynamic.A%", "%Args%", "%Secret(A)%"},
+		},
+		{
+			name:     "args placeholder",
+			task:     config.Task{Command: "cmd", Arguments: []string{"--files=%Args% --end"}},
+			extra:    []string{"a b", "c"},
+			wantCmd:  "cmd",
+			wantArgs: []string{"--files=a b", "c", "--end"},
+		},
+		{
+			name:     "numbered args placeholders",
+			task:     config.Task{Command: "cmd", Arguments: []string{"%Args.2% %Args.1% %Args.3%"}},
+			extra:    []string{"a b", "c"},
+			wantCmd:  "cmd",
+			wantArgs: []string{"c", "a b"},
+		},
+		{
+			name:     "args placeholder without extra arguments",
+			task:     config.Task{Command: "cmd", Arguments: []string{"-v %Args%"}},
+			wantCmd:  "cmd",
+			wantArgs: []string{"-v"},
+		},
+		{
+			name:     "set overrides a dynamic value",
+			task:     config.Task{Command: "cmd", Arguments: []string{"%Dynamic.Dest%"}, Dynamic: map[string]any{"Dest": "old"}},
+			sets:     []string{"Dynamic.Dest=new"},
+			wantCmd:  "cmd",
+			wantArgs: []string{"new"},
+		},
+		{
+			name:     "set adds a dynamic value",
+			task:     config.Task{Command: "%Dynamic.Cmd%"},
+			sets:     []string{"Dynamic.Cmd=run"},
+			wantCmd:  "run",
+			wantArgs: []string{},
+		},
+		{
+			name:      "single values join the extra arguments",
+			task:      config.Task{Command: "cmd", RemovePathAfterJobCompletes: "/tmp/%Args%"},
+			extra:     []string{"a", "b"},
+			wantCmd:   "cmd",
+			wantArgs:  []string{"a", "b"},
+			wantValue: "/tmp/a b",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o, err := parseOverrides(tt.sets, tt.extra)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			conf := config.CurrentSnapshot()
+			task := o.applyTask(tt.task)
+			cmd, args, err := buildCommand(task, conf, task.Command, task.Arguments)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if cmd != tt.wantCmd {
+				t.Errorf("command = %q, want %q", cmd, tt.wantCmd)
+			}
+			if !reflect.DeepEqual(args, tt.wantArgs) {
+				t.Errorf("arguments = %q, want %q", args, tt.wantArgs)
+			}
+			if v, _ := resolveValue(task, conf, task.RemovePathAfterJobCompletes); v != tt.wantValue {
+				t.Errorf("value = %q, want %q", v, tt.wantValue)
+			}
+		})
+	}
+}
+
+func TestApplyGlobalOverrides(t *testing.T) {
+	o, err := parseOverrides([]string{"GlobalDynamic.Host=staging"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	conf := config.CurrentSnapshot()
+	overridden := o.applyGlobal(conf)
+	task := config.Task{Command: "cmd", Arguments: []string{"%GlobalDynamic.Host%"}}
+	_, args, err := buildCommand(task, overridden, task.Command, task.Arguments)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(args, []string{"staging"}) {
+		t.Errorf("arguments = %q, want [\"staging\"]", args)
+	}
+	if _, ok := conf.GlobalDynamic()["Host"]; ok {
+		t.Error("the original snapshot has been modified")
+	}
+}
@@ -7,6 +7,17 @@ import (
 	"time"
 )
 
+var (
+	// dateTokens contains every token of ParseDate.
+	// Values need to be ordered to get consistent and correct results.
+	dateTokens = []string{
+		"YYYY", "YYY", "YY", "MMMM", "MMM", "MM", "M", "DDDD", "DDD", "DD", "D",
+		"hha", "hh", "h", "mm", "m", "ss", "s", "ms",
+	}
+
+	lettersReg = regexp.MustCompile(`\pL+`)
+)
+
 func ParseDate(tm time.Time, format string) (date string, err error) {
 	date = format
 	formats := map[string]string{
@@ -30,14 +41,8 @@ func ParseDate(tm time.Time, format string) (date string, err error) {
 		"s":    fmt.Sprintf("%d", tm.Second()),
 		"ms":   fmt.Sprintf("%d", int32(tm.Nanosecond())/int32(time.Millisecond)),
 	}
-	// Values need to be ordered to get consistent and correct results.
-	ordered := []string{
-		"YYYY", "YYY", "YY", "MMMM", "MMM", "MM", "M", "DDDD", "DDD", "DD", "D",
-		"hha", "hh", "h", "mm", "m", "ss", "s", "ms",
-	}
-
 	var reg *regexp.Regexp
-	for _, f := range ordered {
+	for _, f := range dateTokens {
 		reg, err = regexp.Compile(fmt.Sprintf("(%s)", regexp.QuoteMeta(f)))
 		if err != nil {
 			return
@@ -50,3 +55,37 @@ func ParseDate(tm time.Time, format string) (date string, err error) {
 	}
 	return
 }
+
+// DateTokens returns every token supported by ParseDate.
+func DateTokens() []string {
+	return append([]string(nil), dateTokens...)
+}
+
+// UnknownDateTokens returns every run of letters inside format which can not be split into tokens of ParseDate.
+// ParseDate keeps them as they are, but parts of them may be replaced, e.g. the "s" of "secs".
+func UnknownDateTokens(format string) (unknown []string) {
+	for _, run := range lettersReg.FindAllString(format, -1) {
+		if !isDateTokens(run) {
+			unknown = append(unknown, run)
+		}
+	}
+	return
+}
+
+// isDateTokens returns whether s consists of tokens of ParseDate only.
+func isDateTokens(s string) bool {
+	// split[i] is true if s[:i] can be split into tokens.
+	split := make([]bool, len(s)+1)
+	split[0] = true
+	for i := range s {
+		if !split[i] {
+			continue
+		}
+		for _, t := range dateTokens {
+			if strings.HasPrefix(s[i:], t) {
+				split[i+len(t)] = true
+			}
+		}
+	}
+	return split[len(s)]
+}
@@ -0,0 +1,224 @@
+package main
+
+import (
+	"WrapNGo/config"
+	"WrapNGo/logger"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+const (
+	statusSucceeded = "succeeded"
+	statusFailed    = "failed"
+	statusSkipped   = "skipped"
+)
+
+// The runOptions type defines how multiple tasks are run.
+type runOptions struct {
+	// parallel is the maximum number of tasks running at the same time.
+	// 1 runs the tasks sequentially in the given order (dependencies first), 0 starts all tasks at once.
+	parallel int
+
+	// keepGoing defines whether remaining tasks are started after a task failed.
+	// Tasks depending on the failed task are never started.
+	keepGoing bool
+
+	// dryRun only prints the resolved commands instead of executing them.
+	dryRun bool
+}
+
+// The taskResult type contains the outcome of a single task.
+type taskResult struct {
+	Name            string  `json:"Name"`
+	Source          string  `json:"Source"`
+	Status          string  `json:"Status"`
+	ExitCode        int     `json:"ExitCode"`
+	Error           string  `json:"Error,omitempty"`
+	DurationSeconds float64 `json:"DurationSeconds"`
+}
+
+// The runError type is returned if at least one task failed.
+type runError struct {
+	results []taskResult
+}
+
+func (e *runError) Error() string {
+	failed := make([]string, 0)
+	for _, r := range e.results {
+		if r.Status == statusFailed {
+			failed = append(failed, r.Name)
+		}
+	}
+	return fmt.Sprintf("%v: %s", ErrTasksFailed, strings.Join(failed, ", "))
+}
+
+// exitCode returns the exit code with the highest precedence of all failed tasks.
+func (e *runError) exitCode() (code int) {
+	for _, r := range e.results {
+		code = precedingExitCode(code, r.ExitCode)
+	}
+	if code == exitOK {
+		code = exitFailure
+	}
+	return
+}
+
+// The states of a task scheduled by runTasks.
+const (
+	taskWaiting = iota
+	taskRunning
+	taskDone
+)
+
+// The taskFinished type is sent by a task once it has finished.
+type taskFinished struct {
+	i   int
+	err error
+}
+
+// runTasks runs the given tasks as defined by opts and blocks until all started tasks have finished.
+// A task is only started after every given task it depends on (see config.Task.DependsOn) has succeeded,
+// tasks whose dependencies failed or have been skipped are skipped as well, even if opts.keepGoing is set.
+// Tasks which do not depend on each other run in parallel as far as opts.parallel allows.
+// The returned results contain an entry for every given task in the same order.
+func runTasks(tasks []config.Task, conf *config.Snapshot, opts runOptions) (results []taskResult, err error) {
+	limit := opts.parallel
+	if limit < 1 || limit > len(tasks) {
+		limit = len(tasks)
+	}
+
+	results = make([]taskResult, len(tasks))
+	for i, t := range tasks {
+		results[i] = taskResult{Name: t.Name, Source: t.Source, Status: statusSkipped}
+	}
+
+	deps := dependencyGraph(tasks, conf.GeneralSettings().CaseSensitiveJobNames)
+	state := make([]int, len(tasks))
+	// blocker returns the first dependency of the task i which did not succeed, -1 if there is none.
+	// ready is false as long as a dependency has not finished yet.
+	blocker := func(i int) (dep int, ready bool) {
+		ready = true
+		for _, j := range deps[i] {
+			if state[j] != taskDone {
+				ready = false
+				continue
+			}
+			if results[j].Status != statusSucceeded {
+				return j, false
+			}
+		}
+		return -1, ready
+	}
+
+	var (
+		failed  bool
+		itr     bool
+		running int
+	)
+	finished := make(chan taskFinished)
+	for {
+		// Skipping a task skips the tasks depending on it as well.
+		for skipped := true; skipped; {
+			skipped = false
+			for i, t := range tasks {
+				if state[i] != taskWaiting {
+					continue
+				}
+				j, _ := blocker(i)
+				if j < 0 {
+					continue
+				}
+				reason := "failed"
+				if results[j].Status == statusSkipped {
+					reason = "has been skipped"
+				}
+				results[i].Error = fmt.Sprintf("dependency \"%s\" %s", tasks[j].Name, reason)
+				logger.Warnf("%s: Task skipped, %s\n", t.Name, results[i].Error)
+				state[i] = taskDone
+				skipped = true
+			}
+		}
+
+		// Do not start any further task after a failure or an interrupt.
+		stop := itr || (failed && !opts.keepGoing)
+		for i, t := range tasks {
+			if stop || running >= limit {
+				break
+			}
+			_, ready := blocker(i)
+			if state[i] != taskWaiting || !ready {
+				continue
+			}
+
+			state[i] = taskRunning
+			running++
+			if limit > 1 {
+				logger.Infof("Starting Task \"%s\" in the background.\n", t.Name)
+			} else {
+				logger.Infof("Starting Task \"%s\".\n", t.Name)
+			}
+			go func(i int, t config.Task) {
+				run := RunTask
+				if opts.dryRun {
+					run = dryRunTask
+				}
+				start := time.Now()
+				tErr := run(t, conf)
+
+				// Each goroutine only writes its own result, it is read after the task has finished.
+				r := &results[i]
+				r.DurationSeconds = time.Since(start).Seconds()
+				r.Status = statusSucceeded
+				if tErr != nil {
+					logger.Error(tErr)
+					r.Status = statusFailed
+					r.Error = tErr.Error()
+					r.ExitCode = exitCode(tErr)
+				}
+				logger.Infof("%s: Task finished\n", t.Name)
+				finished <- taskFinished{i: i, err: tErr}
+			}(i, t)
+		}
+
+		// Nothing is left to run, the remaining tasks stay skipped.
+		if running == 0 {
+			break
+		}
+		f := <-finished
+		running--
+		state[f.i] = taskDone
+		if f.err != nil {
+			failed = true
+			itr = itr || errors.Is(f.err, ErrUserInterrupt)
+		}
+	}
+
+	if failed {
+		err = &runError{results: results}
+	}
+	return
+}
+
+// writeSummary writes the results of a run as json to w.
+// Secrets inside the errors are masked before encoding, the escaping of json would keep them from being masked by w.
+func writeSummary(w io.Writer, results []taskResult, code int) error {
+	tasks := make([]taskResult, len(results))
+	for i, r := range results {
+		r.Error = logger.Masked(r.Error)
+		tasks[i] = r
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(struct {
+		ExitCode int          `json:"ExitCode"`
+		Tasks    []taskResult `json:"Tasks"`
+	}{
+		ExitCode: code,
+		Tasks:    tasks,
+	})
+}
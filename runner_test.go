@@ -0,0 +1,282 @@
+package main
+
+import (
+	"WrapNGo/config"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestRunTasks(t *testing.T) {
+	// marker is created by the task "create" and checked by the tasks which need to run after it.
+	marker := filepath.Join(t.TempDir(), "marker")
+	create := config.Task{Name: "create", Command: "touch", Arguments: []string{marker}}
+	check := func(name string, deps ...string) config.Task {
+		return config.Task{Name: name, Command: "test", Arguments: []string{"-e", marker}, DependsOn: deps}
+	}
+	fail := func(name string, deps ...string) config.Task {
+		return config.Task{Name: name, Command: "false", StopIfUnsuccessful: true, DependsOn: deps}
+	}
+	succeed := func(name string, deps ...string) config.Task {
+		return config.Task{Name: name, Command: "true", DependsOn: deps}
+	}
+	// parallelOp returns a task whose parallel PreOperation fails after its job has finished.
+	parallelOp := func(name string, stop bool) config.Task {
+		op := config.Operation{Enabled: true, StopIfUnsuccessful: stop, Command: "sh", Arguments: []string{"-c", `sleep\ 0.2;false`}}
+		return config.Task{Name: name, Command: "true", AllowParallelOperationsRun: true, PreOperations: []config.Operation{op}}
+	}
+
+	tests := []struct {
+		name     string
+		tasks    []config.Task
+		opts     runOptions
+		statuses []string
+		code     int
+		skipped  map[string]string
+	}{
+		{
+			name:     "all succeed",
+			tasks:    []config.Task{succeed("a"), succeed("b")},
+			opts:     runOptions{parallel: 1},
+			statuses: []string{statusSucceeded, statusSucceeded},
+		},
+		{
+			name:     "failed job fails the task",
+			tasks:    []config.Task{fail("a"), succeed("b")},
+			opts:     runOptions{parallel: 1, keepGoing: true},
+			statuses: []string{statusFailed, statusSucceeded},
+			code:     exitJobFailed,
+		},
+		{
+			name:     "failed job without StopIfUnsuccessful is only logged",
+			tasks:    []config.Task{{Name: "a", Command: "false"}, succeed("b", "a")},
+			opts:     runOptions{parallel: 1},
+			statuses: []string{statusSucceeded, statusSucceeded},
+		},
+		{
+			name:     "parallel operation failing after the job fails the task",
+			tasks:    []config.Task{parallelOp("a", true), succeed("b", "a")},
+			opts:     runOptions{parallel: 1, keepGoing: true},
+			statuses: []string{statusFailed, statusSkipped},
+			code:     exitOperationFailed,
+		},
+		{
+			name:     "parallel operation without StopIfUnsuccessful is only logged",
+			tasks:    []config.Task{parallelOp("a", false)},
+			opts:     runOptions{parallel: 1},
+			statuses: []string{statusSucceeded},
+		},
+		{
+			name:     "failure stops the remaining tasks",
+			tasks:    []config.Task{fail("a"), succeed("b")},
+			opts:     runOptions{parallel: 1},
+			statuses: []string{statusFailed, statusSkipped},
+			code:     exitJobFailed,
+		},
+		{
+			name:     "dependency runs first in parallel mode",
+			tasks:    []config.Task{check("upload", "db dump", "app dump"), check("db dump", "create"), check("app dump", "create"), create},
+			opts:     runOptions{parallel: 0},
+			statuses: []string{statusSucceeded, statusSucceeded, statusSucceeded, statusSucceeded},
+		},
+		{
+			name:     "dependency runs first in sequential mode",
+			tasks:    []config.Task{check("upload", "create"), create},
+			opts:     runOptions{parallel: 1},
+			statuses: []string{statusSucceeded, statusSucceeded},
+		},
+		{
+			name:     "failed dependency skips its dependents",
+			tasks:    []config.Task{fail("b"), succeed("c", "b"), succeed("d", "c"), succeed("e")},
+			opts:     runOptions{parallel: 0, keepGoing: true},
+			statuses: []string{statusFailed, statusSkipped, statusSkipped, statusSucceeded},
+			code:     exitJobFailed,
+			skipped: map[string]string{
+				"c": `dependency "b" failed`,
+				"d": `dependency "c" has been skipped`,
+			},
+		},
+		{
+			name:     "dependency names are case-insensitive",
+			tasks:    []config.Task{fail("B"), succeed("c", "b")},
+			opts:     runOptions{parallel: 1, keepGoing: true},
+			statuses: []string{statusFailed, statusSkipped},
+			code:     exitJobFailed,
+		},
+		{
+			name:     "dependencies not part of the run are ignored",
+			tasks:    []config.Task{succeed("c", "missing")},
+			opts:     runOptions{parallel: 1},
+			statuses: []string{statusSucceeded},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_ = os.Remove(marker)
+			results, err := runTasks(tt.tasks, config.CurrentSnapshot(), tt.opts)
+			statuses := make([]string, len(results))
+			for i, r := range results {
+				statuses[i] = r.Status
+				if tt.skipped[r.Name] != "" && r.Error != tt.skipped[r.Name] {
+					t.Errorf("error of %s = %q, want %q", r.Name, r.Error, tt.skipped[r.Name])
+				}
+			}
+			if !reflect.DeepEqual(statuses, tt.statuses) {
+				t.Errorf("statuses = %v, want %v", statuses, tt.statuses)
+			}
+
+			var rErr *runError
+			switch {
+			case tt.code == exitOK && err != nil:
+				t.Errorf("unexpected error: %v", err)
+			case tt.code != exitOK && !errors.As(err, &rErr):
+				t.Errorf("error = %v, want a *runError", err)
+			case exitCode(err) != tt.code:
+				t.Errorf("exit code = %d, want %d", exitCode(err), tt.code)
+			}
+		})
+	}
+}
+
+func TestDependencyGraph(t *testing.T) {
+	task := func(name string, deps ...string) config.Task {
+		return config.Task{Name: name, DependsOn: deps}
+	}
+	tests := []struct {
+		name          string
+		tasks         []config.Task
+		caseSensitive bool
+		want          [][]int
+	}{
+		{
+			name:  "no dependencies",
+			tasks: []config.Task{task("a"), task("b")},
+			want:  [][]int{nil, nil},
+		},
+		{
+			name:  "diamond",
+			tasks: []config.Task{task("mount"), task("db", "mount"), task("app", "mount"), task("upload", "db", "app")},
+			want:  [][]int{nil, {0}, {0}, {1, 2}},
+		},
+		{
+			name:  "duplicate names depend on all tasks",
+			tasks: []config.Task{task("dump"), task("dump"), task("upload", "dump")},
+			want:  [][]int{nil, nil, {0, 1}},
+		},
+		{
+			name:  "unknown dependencies and the task itself are ignored",
+			tasks: []config.Task{task("a", "a", "missing")},
+			want:  [][]int{nil},
+		},
+		{
+			name:  "case-insensitive names",
+			tasks: []config.Task{task("Mount"), task("db", "mount")},
+			want:  [][]int{nil, {0}},
+		},
+		{
+			name:          "case-sensitive names",
+			tasks:         []config.Task{task("Mount"), task("db", "mount")},
+			caseSensitive: true,
+			want:          [][]int{nil, nil},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := dependencyGraph(tt.tasks, tt.caseSensitive)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("dependencyGraph() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRunError(t *testing.T) {
+	err := &runError{results: []taskResult{
+		{Name: "a", Status: statusFailed, ExitCode: exitJobFailed},
+		{Name: "b", Status: statusSkipped},
+		{Name: "c", Status: statusFailed, ExitCode: exitTimeout},
+	}}
+	if !strings.HasSuffix(err.Error(), ": a, c") {
+		t.Errorf("Error() = %q, want the failed tasks a and c", err.Error())
+	}
+	if err.exitCode() != exitTimeout {
+		t.Errorf("exitCode() = %d, want %d", err.exitCode(), exitTimeout)
+	}
+}
+
+func TestRunTasksOrder(t *testing.T) {
+	// Each task appends its name to the file "order" and the number of tasks running beside it to the file "running".
+	task := func(dir, name string) config.Task {
+		s := strings.Join([]string{
+			"cd " + dir,
+			"echo " + name + " >> order",
+			"ls active | wc -l >> running",
+			"touch active/" + name,
+			"sleep 0.05",
+			"rm active/" + name,
+		}, ";")
+		return config.Task{Name: name, Command: "sh", Arguments: []string{"-c", strings.ReplaceAll(s, " ", "\\ ")}, StopIfUnsuccessful: true}
+	}
+
+	names := []string{"a", "b", "c", "d"}
+	tests := []struct {
+		name     string
+		parallel int
+		limit    int
+		ordered  bool
+	}{
+		{name: "sequential keeps the given order", parallel: 1, limit: 1, ordered: true},
+		{name: "parallel limit", parallel: 2, limit: 2},
+		{name: "no limit", parallel: 0, limit: len(names)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			err := os.Mkdir(filepath.Join(dir, "active"), 0700)
+			if err != nil {
+				t.Fatal(err)
+			}
+			tasks := make([]config.Task, len(names))
+			for i, n := range names {
+				tasks[i] = task(dir, n)
+			}
+			_, err = runTasks(tasks, config.CurrentSnapshot(), runOptions{parallel: tt.parallel})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			b, err := os.ReadFile(filepath.Join(dir, "order"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			order := strings.Fields(string(b))
+			sorted := append([]string(nil), order...)
+			sort.Strings(sorted)
+			if !reflect.DeepEqual(sorted, names) {
+				t.Fatalf("order = %v, want every task once", order)
+			}
+			if tt.ordered && !reflect.DeepEqual(order, names) {
+				t.Errorf("order = %v, want %v", order, names)
+			}
+
+			b, err = os.ReadFile(filepath.Join(dir, "running"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, r := range strings.Fields(string(b)) {
+				n, err := strconv.Atoi(r)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if n >= tt.limit {
+					t.Errorf("a task started beside %d running tasks, want at most %d", n, tt.limit-1)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,190 @@
+package secret
+
+import (
+	"WrapNGo/config"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const (
+	// EnvDir is the environment variable overriding the directory containing one file per secret.
+	EnvDir = "WRAPNGO_SECRETS_DIR"
+
+	// EnvPassphrase is the environment variable containing the passphrase of the encrypted store.
+	EnvPassphrase = "WRAPNGO_SECRETS_PASSPHRASE"
+
+	// EnvPrefix is the prefix of the environment variables containing a single secret, e.g. WRAPNGO_SECRET_DB_PASSWORD.
+	EnvPrefix = "WRAPNGO_SECRET_"
+
+	// SourceDir is the source of a secret read from the secrets directory.
+	SourceDir = "dir"
+
+	// SourceEnv is the source of a secret read from its environment variable.
+	SourceEnv = "env"
+
+	// SourceStore is the source of a secret read from the encrypted store.
+	SourceStore = "store"
+
+	dirName       = "secrets"
+	storeFileName = "secrets.store"
+)
+
+var (
+	// ErrNotFound is returned if a secret is not defined in any source.
+	ErrNotFound = errors.New("secret not found")
+
+	// ErrInvalidName is returned if the name of a secret contains other characters than letters, digits, '_' and '-'.
+	ErrInvalidName = errors.New("invalid secret name")
+)
+
+// The Entry type describes a single secret without its value.
+type Entry struct {
+	// Name is the name used by the %Secret(name)% placeholder.
+	Name   string `json:"Name" yaml:"Name"`
+	Source string `json:"Source" yaml:"Source"`
+
+	// Location is the file or environment variable the secret is read from.
+	Location string `json:"Location" yaml:"Location"`
+}
+
+// Get returns the value of the given secret and the source it has been read from.
+// The sources are checked in the following order: the secrets directory, the environment and the encrypted store.
+func Get(name string) (value, source string, err error) {
+	err = checkName(name)
+	if err != nil {
+		return
+	}
+
+	dir, err := Dir()
+	if err != nil {
+		return
+	}
+	b, err := os.ReadFile(filepath.Join(dir, name))
+	if err == nil {
+		return strings.TrimRight(string(b), "\r\n"), SourceDir, nil
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		return
+	}
+
+	value, ok := os.LookupEnv(EnvName(name))
+	if ok {
+		return value, SourceEnv, nil
+	}
+
+	exists, err := storeExists()
+	if err != nil || !exists {
+		return "", "", fmt.Errorf("%w: %s", ErrNotFound, name)
+	}
+	s, err := openStore(false)
+	if err != nil {
+		return
+	}
+	value, ok = s.values[name]
+	if !ok {
+		return "", "", fmt.Errorf("%w: %s", ErrNotFound, name)
+	}
+	return value, SourceStore, nil
+}
+
+// Set stores the given secret inside the encrypted store, the store is created if it does not exist yet.
+func Set(name, value string) (err error) {
+	err = checkName(name)
+	if err != nil {
+		return
+	}
+	s, err := openStore(true)
+	if err != nil {
+		return
+	}
+	s.values[name] = value
+	return s.save()
+}
+
+// List returns every secret of all sources, sorted by name.
+// A secret defined in multiple sources is listed once for each source.
+// Secrets of the environment are listed by their lower-cased name, e.g. db_password for WRAPNGO_SECRET_DB_PASSWORD.
+// The encrypted store is only opened if it exists.
+func List() (entries []Entry, err error) {
+	dir, err := Dir()
+	if err != nil {
+		return
+	}
+	files, err := os.ReadDir(dir)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return
+	}
+	for _, f := range files {
+		if !f.IsDir() && config.IsSecretName(f.Name()) {
+			entries = append(entries, Entry{Name: f.Name(), Source: SourceDir, Location: filepath.Join(dir, f.Name())})
+		}
+	}
+
+	for _, env := range os.Environ() {
+		key, _, _ := strings.Cut(env, "=")
+		name := strings.ToLower(strings.TrimPrefix(key, EnvPrefix))
+		if strings.HasPrefix(key, EnvPrefix) && config.IsSecretName(name) {
+			entries = append(entries, Entry{Name: name, Source: SourceEnv, Location: key})
+		}
+	}
+
+	exists, err := storeExists()
+	if err != nil {
+		return
+	}
+	if exists {
+		var s *store
+		s, err = openStore(false)
+		if err != nil {
+			return
+		}
+		for name := range s.values {
+			entries = append(entries, Entry{Name: name, Source: SourceStore, Location: s.path})
+		}
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Name < entries[j].Name
+	})
+	return entries, nil
+}
+
+// Dir returns the directory containing one file per secret, named after the secret.
+// It defaults to the "secrets" directory inside the config directory.
+func Dir() (dir string, err error) {
+	dir = os.Getenv(EnvDir)
+	if dir != "" {
+		return filepath.Abs(dir)
+	}
+	dir, err = config.Dir()
+	if err != nil {
+		return
+	}
+	return filepath.Join(dir, dirName), nil
+}
+
+// StorePath returns the path of the encrypted store inside the config directory.
+func StorePath() (path string, err error) {
+	dir, err := config.Dir()
+	if err != nil {
+		return
+	}
+	return filepath.Join(dir, storeFileName), nil
+}
+
+// EnvName returns the environment variable of the given secret, e.g. WRAPNGO_SECRET_DB_PASSWORD for db-password.
+func EnvName(name string) string {
+	return EnvPrefix + strings.ToUpper(strings.ReplaceAll(name, "-", "_"))
+}
+
+// checkName returns ErrInvalidName if name can not be used as a secret name.
+func checkName(name string) error {
+	if !config.IsSecretName(name) {
+		return fmt.Errorf("%w %q, only letters, digits, '_' and '-' are allowed", ErrInvalidName, name)
+	}
+	return nil
+}
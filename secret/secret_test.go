@@ -0,0 +1,210 @@
+package secret
+
+import (
+	"WrapNGo/config"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// setupSources points every source to an empty temporary directory and resets the opened store.
+func setupSources(t *testing.T) (configDir string) {
+	t.Helper()
+	configDir = t.TempDir()
+	t.Setenv(config.EnvConfigDir, configDir)
+	t.Setenv(EnvDir, "")
+	t.Setenv(EnvPassphrase, "passphrase")
+	for _, env := range os.Environ() {
+		key, _, _ := strings.Cut(env, "=")
+		if strings.HasPrefix(key, EnvPrefix) {
+			t.Setenv(key, "")
+			os.Unsetenv(key)
+		}
+	}
+	resetStore()
+	t.Cleanup(resetStore)
+	return
+}
+
+// resetStore forgets the decrypted store, the next access reads it from its file again.
+func resetStore() {
+	storeMux.Lock()
+	defer storeMux.Unlock()
+	opened = nil
+}
+
+func TestStore(t *testing.T) {
+	setupSources(t)
+	err := Set("db-password", "s3cr3t")
+	if err != nil {
+		t.Fatalf("unable to set the secret: %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		passphrase string
+		secret     string
+		value      string
+		err        error
+	}{
+		{name: "stored", passphrase: "passphrase", secret: "db-password", value: "s3cr3t"},
+		{name: "not found", passphrase: "passphrase", secret: "missing", err: ErrNotFound},
+		{name: "wrong passphrase", passphrase: "wrong", secret: "db-password", err: ErrWrongPassphrase},
+		{name: "no passphrase", secret: "db-password", err: ErrNoPassphrase},
+		{name: "invalid name", passphrase: "passphrase", secret: "../config.json", err: ErrInvalidName},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resetStore()
+			t.Setenv(EnvPassphrase, tt.passphrase)
+			value, source, err := Get(tt.secret)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("error = %v, want %v", err, tt.err)
+			}
+			if err != nil {
+				return
+			}
+			if value != tt.value || source != SourceStore {
+				t.Errorf("Get() = %q, %q, want %q, %q", value, source, tt.value, SourceStore)
+			}
+		})
+	}
+}
+
+func TestGetSourceOrder(t *testing.T) {
+	tests := []struct {
+		name   string
+		dir    string
+		env    string
+		inEnv  bool
+		store  string
+		value  string
+		source string
+	}{
+		{name: "dir", dir: "dir\n", env: "env", inEnv: true, store: "store", value: "dir", source: SourceDir},
+		{name: "env", env: "env", inEnv: true, store: "store", value: "env", source: SourceEnv},
+		{name: "empty env", inEnv: true, store: "store", value: "", source: SourceEnv},
+		{name: "store", store: "store", value: "store", source: SourceStore},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			configDir := setupSources(t)
+			err := Set("api-token", tt.store)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resetStore()
+			if tt.dir != "" {
+				dir := filepath.Join(configDir, dirName)
+				err = os.MkdirAll(dir, 0700)
+				if err == nil {
+					err = os.WriteFile(filepath.Join(dir, "api-token"), []byte(tt.dir), 0600)
+				}
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+			if tt.inEnv {
+				t.Setenv("WRAPNGO_SECRET_API_TOKEN", tt.env)
+			}
+
+			value, source, err := Get("api-token")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if value != tt.value || source != tt.source {
+				t.Errorf("Get() = %q, %q, want %q, %q", value, source, tt.value, tt.source)
+			}
+		})
+	}
+}
+
+func TestList(t *testing.T) {
+	configDir := setupSources(t)
+	secretsDir := t.TempDir()
+	t.Setenv(EnvDir, secretsDir)
+	for _, name := range []string{"mail", "not a secret"} {
+		err := os.WriteFile(filepath.Join(secretsDir, name), []byte("x"), 0600)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Setenv("WRAPNGO_SECRET_DB_PASSWORD", "x")
+	t.Setenv("WRAPNGO_SECRET_", "x")
+	err := Set("api-token", "x")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := List()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []Entry{
+		{Name: "api-token", Source: SourceStore, Location: filepath.Join(configDir, storeFileName)},
+		{Name: "db_password", Source: SourceEnv, Location: "WRAPNGO_SECRET_DB_PASSWORD"},
+		{Name: "mail", Source: SourceDir, Location: filepath.Join(secretsDir, "mail")},
+	}
+	if !reflect.DeepEqual(entries, want) {
+		t.Errorf("List() = %+v, want %+v", entries, want)
+	}
+}
+
+func TestStoreParameters(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(f map[string]any)
+		err    error
+	}{
+		{name: "unchanged", modify: func(map[string]any) {}},
+		{name: "huge N", modify: func(f map[string]any) { f["N"] = 1 << 40 }, err: ErrInvalidStore},
+		{name: "N not a power of two", modify: func(f map[string]any) { f["N"] = 3 }, err: ErrInvalidStore},
+		{name: "huge R", modify: func(f map[string]any) { f["R"] = 1 << 20 }, err: ErrInvalidStore},
+		{name: "huge P", modify: func(f map[string]any) { f["P"] = 1 << 20 }, err: ErrInvalidStore},
+		{name: "zero parameters", modify: func(f map[string]any) { f["N"], f["R"], f["P"] = 0, 0, 0 }, err: ErrInvalidStore},
+		{name: "version", modify: func(f map[string]any) { f["Version"] = 2 }, err: ErrInvalidStore},
+		{name: "short nonce", modify: func(f map[string]any) { f["Nonce"] = "AAAA" }, err: ErrInvalidStore},
+		{name: "short salt", modify: func(f map[string]any) { f["Salt"] = "AAAA" }, err: ErrInvalidStore},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setupSources(t)
+			err := Set("token", "value")
+			if err != nil {
+				t.Fatalf("unable to set the secret: %v", err)
+			}
+			path, err := StorePath()
+			if err != nil {
+				t.Fatal(err)
+			}
+			b, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var f map[string]any
+			err = json.Unmarshal(b, &f)
+			if err != nil {
+				t.Fatal(err)
+			}
+			tt.modify(f)
+			b, err = json.Marshal(f)
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = os.WriteFile(path, b, 0600)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			resetStore()
+			_, _, err = Get("token")
+			if !errors.Is(err, tt.err) {
+				t.Errorf("error = %v, want %v", err, tt.err)
+			}
+		})
+	}
+}
@@ -0,0 +1,240 @@
+package secret
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"golang.org/x/crypto/scrypt"
+)
+
+const (
+	storeVersion = 1
+	keyLength    = 32
+	saltLength   = 16
+
+	// nonceLength is the standard nonce length of AES-GCM.
+	nonceLength = 12
+
+	// The scrypt parameters recommended for interactive logins.
+	scryptN = 1 << 15
+	scryptR = 8
+	scryptP = 1
+)
+
+var (
+	// ErrNoPassphrase is returned if the encrypted store is needed but no passphrase is available.
+	ErrNoPassphrase = errors.New("the passphrase of the secret store is required, set " + EnvPassphrase)
+
+	// ErrInvalidStore is returned if the file of the encrypted store has not been written by this version of the store.
+	ErrInvalidStore = errors.New("invalid secret store")
+
+	// ErrWrongPassphrase is returned if the encrypted store can not be decrypted.
+	ErrWrongPassphrase = errors.New("wrong passphrase or corrupted secret store")
+
+	// Prompt asks for the passphrase of the encrypted store if EnvPassphrase is unset.
+	// create is true if the store does not exist yet and the passphrase should be confirmed.
+	// If Prompt is nil, ErrNoPassphrase is returned instead.
+	Prompt func(create bool) (passphrase string, err error)
+
+	storeMux sync.Mutex
+	opened   *store
+)
+
+// The storeFile type is the encoded representation of the encrypted store.
+// Data contains the secrets encrypted with AES-256-GCM, the key is derived from the passphrase via scrypt.
+type storeFile struct {
+	Version int    `json:"Version"`
+	N       int    `json:"N"`
+	R       int    `json:"R"`
+	P       int    `json:"P"`
+	Salt    []byte `json:"Salt"`
+	Nonce   []byte `json:"Nonce"`
+	Data    []byte `json:"Data"`
+}
+
+// check returns an error if f has not been written by this version of the store.
+// The scrypt parameters are only accepted if they equal the ones used to create a store,
+// a modified file must not be able to demand an arbitrary amount of memory or CPU time.
+func (f storeFile) check() error {
+	switch {
+	case f.Version != storeVersion:
+		return fmt.Errorf("%w: unsupported version %d", ErrInvalidStore, f.Version)
+	case f.N != scryptN || f.R != scryptR || f.P != scryptP:
+		return fmt.Errorf("%w: unsupported scrypt parameters N=%d, R=%d, P=%d, expected N=%d, R=%d, P=%d", ErrInvalidStore, f.N, f.R, f.P, scryptN, scryptR, scryptP)
+	case len(f.Salt) != saltLength:
+		return fmt.Errorf("%w: salt length %d, expected %d", ErrInvalidStore, len(f.Salt), saltLength)
+	case len(f.Nonce) != nonceLength:
+		return fmt.Errorf("%w: nonce length %d, expected %d", ErrInvalidStore, len(f.Nonce), nonceLength)
+	}
+	return nil
+}
+
+// The store type is the decrypted store.
+type store struct {
+	path   string
+	file   storeFile
+	key    []byte
+	values map[string]string
+}
+
+// storeExists returns whether the encrypted store has been created.
+func storeExists() (exists bool, err error) {
+	path, err := StorePath()
+	if err != nil {
+		return
+	}
+	_, err = os.Stat(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// openStore decrypts the store, it is only decrypted once per process.
+// If create is set, a new empty store is returned if it does not exist yet.
+func openStore(create bool) (s *store, err error) {
+	storeMux.Lock()
+	defer storeMux.Unlock()
+	if opened != nil {
+		return opened, nil
+	}
+
+	path, err := StorePath()
+	if err != nil {
+		return
+	}
+	b, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) && create {
+		s, err = newStore(path)
+		if err == nil {
+			opened = s
+		}
+		return
+	}
+	if err != nil {
+		return
+	}
+
+	s = &store{path: path}
+	err = json.Unmarshal(b, &s.file)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read the secret store %s: %v", path, err)
+	}
+	err = s.file.check()
+	if err != nil {
+		return nil, fmt.Errorf("unable to read the secret store %s: %w", path, err)
+	}
+
+	passphrase, err := passphrase(false)
+	if err != nil {
+		return
+	}
+	s.key, err = scrypt.Key([]byte(passphrase), s.file.Salt, s.file.N, s.file.R, s.file.P, keyLength)
+	if err != nil {
+		return
+	}
+	aead, err := newAEAD(s.key)
+	if err != nil {
+		return
+	}
+	plain, err := aead.Open(nil, s.file.Nonce, s.file.Data, nil)
+	if err != nil {
+		return nil, ErrWrongPassphrase
+	}
+	err = json.Unmarshal(plain, &s.values)
+	if err != nil {
+		return nil, ErrWrongPassphrase
+	}
+	if s.values == nil {
+		s.values = make(map[string]string)
+	}
+	opened = s
+	return
+}
+
+// newStore creates a new empty store, the passphrase is asked for with confirmation.
+func newStore(path string) (s *store, err error) {
+	s = &store{
+		path:   path,
+		file:   storeFile{Version: storeVersion, N: scryptN, R: scryptR, P: scryptP, Salt: make([]byte, saltLength)},
+		values: make(map[string]string),
+	}
+	_, err = rand.Read(s.file.Salt)
+	if err != nil {
+		return
+	}
+
+	passphrase, err := passphrase(true)
+	if err != nil {
+		return
+	}
+	s.key, err = scrypt.Key([]byte(passphrase), s.file.Salt, s.file.N, s.file.R, s.file.P, keyLength)
+	return
+}
+
+// save encrypts the store with a new nonce and replaces the file.
+func (s *store) save() (err error) {
+	plain, err := json.Marshal(s.values)
+	if err != nil {
+		return
+	}
+	aead, err := newAEAD(s.key)
+	if err != nil {
+		return
+	}
+	s.file.Nonce = make([]byte, aead.NonceSize())
+	_, err = rand.Read(s.file.Nonce)
+	if err != nil {
+		return
+	}
+	s.file.Data = aead.Seal(nil, s.file.Nonce, plain, nil)
+
+	b, err := json.MarshalIndent(s.file, "", "\t")
+	if err != nil {
+		return
+	}
+	err = os.MkdirAll(filepath.Dir(s.path), 0700)
+	if err != nil {
+		return
+	}
+
+	// Write to a temporary file first, an interrupted write must not destroy the store.
+	tmp := s.path + ".tmp"
+	err = os.WriteFile(tmp, b, 0600)
+	if err != nil {
+		return
+	}
+	return os.Rename(tmp, s.path)
+}
+
+// passphrase returns the passphrase of EnvPassphrase or asks for it via Prompt.
+func passphrase(create bool) (p string, err error) {
+	p = os.Getenv(EnvPassphrase)
+	if p != "" {
+		return
+	}
+	if Prompt == nil {
+		return "", ErrNoPassphrase
+	}
+	p, err = Prompt(create)
+	if err == nil && p == "" {
+		err = ErrNoPassphrase
+	}
+	return
+}
+
+// newAEAD returns the AES-256-GCM cipher of key.
+func newAEAD(key []byte) (aead cipher.AEAD, err error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return
+	}
+	return cipher.NewGCM(block)
+}
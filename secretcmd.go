@@ -0,0 +1,160 @@
+package main
+
+import (
+	"WrapNGo/logger"
+	"WrapNGo/secret"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/AlecAivazis/survey/v2"
+	"golang.org/x/term"
+)
+
+// promptStdio prints the prompts to stderr, stdout only contains the output of a command (e.g. secret get).
+var promptStdio = survey.WithStdio(os.Stdin, os.Stderr, os.Stderr)
+
+func init() {
+	secret.Prompt = promptPassphrase
+
+	parent := &command{
+		name:    "secret",
+		args:    "<subcommand> [flags] [arguments]",
+		summary: "Manage the secrets used by %Secret(name)% placeholders",
+	}
+	parent.completeWords = parent.subcommandNames
+	registerCommand(parent)
+	registerSubcommand("secret", &command{
+		name:    "set",
+		args:    "<name>",
+		summary: "Store a secret inside the encrypted store, the value is read from stdin",
+		setup:   setupSecretSet,
+	})
+	registerSubcommand("secret", &command{
+		name:    "get",
+		args:    "<name>",
+		summary: "Print the value of a secret",
+		setup:   setupSecretGet,
+	})
+	registerSubcommand("secret", &command{
+		name:    "list",
+		summary: "List the names and sources of all secrets without their values",
+		setup:   setupSecretList,
+	})
+}
+
+// setupSecretSet registers the flags of the secret set command.
+// The value is never taken from the arguments, they are visible to every user (e.g. via ps).
+func setupSecretSet(_ *flag.FlagSet) func(args []string) error {
+	return func(args []string) (err error) {
+		if len(args) != 1 {
+			return fmt.Errorf("%w: secret set requires exactly one name", errUsage)
+		}
+
+		var value string
+		if isTerminal(os.Stdin) {
+			err = survey.AskOne(&survey.Password{Message: "Value of " + args[0]}, &value, promptStdio)
+			if err != nil {
+				return ErrUserInterrupt
+			}
+		} else {
+			var b []byte
+			b, err = io.ReadAll(os.Stdin)
+			if err != nil {
+				return
+			}
+			value = strings.TrimRight(string(b), "\r\n")
+		}
+		if value == "" {
+			return fmt.Errorf("%w: the value of secret %s is empty", errUsage, args[0])
+		}
+
+		err = secret.Set(args[0], value)
+		if errors.Is(err, secret.ErrInvalidName) {
+			return fmt.Errorf("%w: %v", errUsage, err)
+		}
+		if err != nil {
+			return
+		}
+		path, _ := secret.StorePath()
+		logger.Infof("Secret %s stored in %s\n", args[0], path)
+		return
+	}
+}
+
+// setupSecretGet registers the flags of the secret get command.
+func setupSecretGet(_ *flag.FlagSet) func(args []string) error {
+	return func(args []string) (err error) {
+		if len(args) != 1 {
+			return fmt.Errorf("%w: secret get requires exactly one name", errUsage)
+		}
+		value, _, err := secret.Get(args[0])
+		if errors.Is(err, secret.ErrInvalidName) {
+			return fmt.Errorf("%w: %v", errUsage, err)
+		}
+		if err != nil {
+			return
+		}
+		_, err = fmt.Fprintln(os.Stdout, value)
+		return
+	}
+}
+
+// setupSecretList registers the flags of the secret list command.
+func setupSecretList(fs *flag.FlagSet) func(args []string) error {
+	format := fs.String("format", formatTable, "output format (table, json or yaml)")
+	return func(args []string) (err error) {
+		if len(args) > 0 {
+			return fmt.Errorf("%w: secret list does not take any arguments", errUsage)
+		}
+		entries, err := secret.List()
+		if err != nil {
+			return
+		}
+		if entries == nil {
+			entries = make([]secret.Entry, 0)
+		}
+
+		isTable, err := writeEncoded(os.Stdout, entries, *format)
+		if err != nil || !isTable {
+			return
+		}
+		tw := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(tw, "NAME\tSOURCE\tLOCATION")
+		for _, e := range entries {
+			fmt.Fprintf(tw, "%s\t%s\t%s\n", e.Name, e.Source, e.Location)
+		}
+		return tw.Flush()
+	}
+}
+
+// promptPassphrase asks for the passphrase of the encrypted secret store.
+// A new store requires the passphrase to be confirmed.
+func promptPassphrase(create bool) (passphrase string, err error) {
+	if !isTerminal(os.Stdin) {
+		return "", secret.ErrNoPassphrase
+	}
+	err = survey.AskOne(&survey.Password{Message: "Passphrase of the secret store"}, &passphrase, promptStdio)
+	if err != nil || !create {
+		return
+	}
+
+	confirm := ""
+	err = survey.AskOne(&survey.Password{Message: "Confirm the passphrase"}, &confirm, promptStdio)
+	if err != nil {
+		return
+	}
+	if confirm != passphrase {
+		return "", errors.New("the passphrases do not match")
+	}
+	return
+}
+
+// isTerminal returns whether f is an interactive terminal.
+func isTerminal(f *os.File) bool {
+	return term.IsTerminal(int(f.Fd()))
+}
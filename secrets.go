@@ -0,0 +1,117 @@
+package main
+
+import (
+	"WrapNGo/config"
+	"WrapNGo/logger"
+	"WrapNGo/secret"
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+var secretFuncReg = regexp.MustCompile(
+	fmt.Sprintf("(?i)%sSecret\\(([^)%s]*)\\)%s", config.PlaceholderChar, config.PlaceholderChar, config.PlaceholderChar),
+)
+
+var (
+	secretMux    sync.Mutex
+	secretValues = make(map[string]string)
+)
+
+// secretValue returns the value of the given secret.
+// Each secret is only read once per process, its value is masked in everything printed by the logger.
+func secretValue(name string) (value string, err error) {
+	secretMux.Lock()
+	defer secretMux.Unlock()
+	value, ok := secretValues[name]
+	if ok {
+		return
+	}
+
+	value, source, err := secret.Get(name)
+	if err != nil {
+		return
+	}
+	logger.Mask(value)
+	secretValues[name] = value
+	logger.Debugf("Secret %q resolved from %s\n", name, source)
+	return
+}
+
+// resolveSecrets reads every secret referenced by t before anything is started.
+// A missing secret fails the task instead of running the commands with an empty value.
+func resolveSecrets(t config.Task, conf *config.Snapshot) (err error) {
+	unresolved, err := unresolvedSecrets(t, conf)
+	if err != nil {
+		return
+	}
+	return unresolvedError(t, unresolved)
+}
+
+// unresolvedError returns the error failing t if secrets could not be read, nil if unresolved is empty.
+func unresolvedError(t config.Task, unresolved []error) error {
+	if len(unresolved) == 0 {
+		return nil
+	}
+	msgs := make([]string, len(unresolved))
+	for i, u := range unresolved {
+		msgs[i] = u.Error()
+	}
+	return fmt.Errorf("%s: %w: unresolved secret(s): %s", t.Name, ErrInvalidConfig, strings.Join(msgs, "; "))
+}
+
+// unresolvedSecrets reads every secret referenced by t and returns an error for each one which could not be read.
+func unresolvedSecrets(t config.Task, conf *config.Snapshot) (unresolved []error, err error) {
+	b, err := json.Marshal(struct {
+		Task          config.Task
+		GlobalDynamic map[string]any
+		GlobalCommand string
+	}{t, conf.GlobalDynamic(), conf.GeneralSettings().GlobalCommand})
+	if err != nil {
+		return
+	}
+	seen := make(map[string]bool)
+	for _, m := range secretFuncReg.FindAllStringSubmatch(string(b), -1) {
+		if seen[m[1]] {
+			continue
+		}
+		seen[m[1]] = true
+		_, sErr := secretValue(m[1])
+		if sErr != nil {
+			unresolved = append(unresolved, fmt.Errorf("%q: %v", m[1], sErr))
+		}
+	}
+	return
+}
+
+// taskEnv returns the environment of the job and operations of t, the environment of WrapNGo extended by taskEnvironment.
+func taskEnv(t config.Task, conf *config.Snapshot) (env []string, err error) {
+	taskEnv, err := taskEnvironment(t, conf)
+	if err != nil {
+		return
+	}
+	return append(os.Environ(), taskEnv...), nil
+}
+
+// taskEnvironment returns the resolved Environment of t as "KEY=value" pairs, sorted by key.
+// Secrets should be passed this way, unlike arguments the environment of a process is not visible to other users.
+func taskEnvironment(t config.Task, conf *config.Snapshot) (env []string, err error) {
+	keys := make([]string, 0, len(t.Environment))
+	for k := range t.Environment {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		var v string
+		v, err = resolveValue(t, conf, t.Environment[k])
+		if err != nil {
+			return
+		}
+		env = append(env, k+"="+v)
+	}
+	return
+}
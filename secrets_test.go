@@ -0,0 +1,152 @@
+package main
+
+import (
+	"WrapNGo/config"
+	"WrapNGo/logger"
+	"WrapNGo/secret"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestSecretPlaceholders(t *testing.T) {
+	dir := setupConfigDir(t, nil)
+	t.Setenv(secret.EnvDir, dir)
+	t.Setenv(secret.EnvName("spaced"), "pass word")
+	t.Setenv(secret.EnvName("placeholder"), "%Dynamic.A% %Secret(spaced)%")
+	conf := config.CurrentSnapshot()
+
+	tests := []struct {
+		name     string
+		task     config.Task
+		wantArgs []string
+		wantEnv  []string
+		err      error
+	}{
+		{
+			name:     "spaces are kept",
+			task:     config.Task{Name: "a", Command: "cmd", Arguments: []string{"-p %Secret(spaced)%"}},
+			wantArgs: []string{"-p", "pass word"},
+		},
+		{
+			name:     "placeholders inside secrets are not replaced",
+			task:     config.Task{Name: "a", Command: "cmd", Arguments: []string{"--token=%Secret(placeholder)%"}, Dynamic: map[string]any{"A": "x"}},
+			wantArgs: []string{"--token=%Dynamic.A% %Secret(spaced)%"},
+		},
+		{
+			name:     "secrets inside dynamic values",
+			task:     config.Task{Name: "a", Command: "cmd", Arguments: []string{"%Dynamic.Pass%"}, Dynamic: map[string]any{"Pass": "%Secret(spaced)%"}},
+			wantArgs: []string{"pass word"},
+		},
+		{
+			name:     "environment",
+			task:     config.Task{Name: "a", Command: "cmd", Environment: map[string]string{"PASS": "%Secret(spaced)%"}},
+			wantArgs: []string{},
+			wantEnv:  []string{"PASS=pass word"},
+		},
+		{
+			name: "missing secret",
+			task: config.Task{Name: "a", Command: "cmd", Arguments: []string{"%Secret(missing)%"}},
+			err:  ErrInvalidConfig,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := resolveSecrets(tt.task, conf)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("error = %v, want %v", err, tt.err)
+			}
+			_, args, err := buildCommand(tt.task, conf, tt.task.Command, tt.task.Arguments)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("error = %v, want %v", err, tt.err)
+			}
+			if err != nil {
+				if exitCode(err) != exitConfig {
+					t.Errorf("exit code = %d, want %d", exitCode(err), exitConfig)
+				}
+				return
+			}
+			if !reflect.DeepEqual(args, tt.wantArgs) {
+				t.Errorf("arguments = %q, want %q", args, tt.wantArgs)
+			}
+			env, err := taskEnvironment(tt.task, conf)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(env, tt.wantEnv) {
+				t.Errorf("environment = %q, want %q", env, tt.wantEnv)
+			}
+		})
+	}
+}
+
+func TestUnresolvedSecretsFailTheTask(t *testing.T) {
+	dir := setupConfigDir(t, nil)
+	t.Setenv(secret.EnvDir, dir)
+	task := config.Task{Name: "a", Command: "true", StopIfUnsuccessful: true, Arguments: []string{"%Secret(unknown)%"}}
+
+	for _, dryRun := range []bool{false, true} {
+		results, err := runTasks([]config.Task{task}, config.CurrentSnapshot(), runOptions{parallel: 1, dryRun: dryRun})
+		if exitCode(err) != exitConfig {
+			t.Errorf("dry run %t: exit code = %d, want %d", dryRun, exitCode(err), exitConfig)
+		}
+		if len(results) != 1 || results[0].Status != statusFailed {
+			t.Errorf("dry run %t: results = %v, want the task to fail", dryRun, results)
+		}
+	}
+}
+
+func TestDryRunMasksSecrets(t *testing.T) {
+	dir := setupConfigDir(t, nil)
+	t.Setenv(secret.EnvDir, dir)
+	value := `pa"ss\wörd`
+	t.Setenv(secret.EnvName("quoted"), value)
+	task := config.Task{
+		Name:        "a",
+		Command:     "cmd",
+		Arguments:   []string{"--pw=%Secret(quoted)%"},
+		Environment: map[string]string{"PW": "%Secret(quoted)%"},
+		PreOperations: []config.Operation{
+			{Enabled: true, Command: "op", Arguments: []string{"%Secret(quoted)%"}},
+		},
+	}
+
+	out := captureOutput(t, func() {
+		err := dryRunTask(task, config.CurrentSnapshot())
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+	})
+	quoted := strconv.Quote(value)
+	for _, leaked := range []string{value, quoted[1 : len(quoted)-1]} {
+		if strings.Contains(out, leaked) {
+			t.Errorf("output contains %q:\n%s", leaked, out)
+		}
+	}
+	for _, want := range []string{`argv ["--pw=***"]`, `["PW=***"]`, `argv ["***"]`} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output does not contain %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestSummaryMasksSecrets(t *testing.T) {
+	value := `sum"mary\sëcret`
+	logger.Mask(value)
+	buf := &bytes.Buffer{}
+	err := writeSummary(logger.MaskWriter(buf), []taskResult{{Name: "a", Status: statusFailed, Error: "a: login as " + value + " failed"}}, exitJobFailed)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	b, _ := json.Marshal(value)
+	if strings.Contains(buf.String(), string(b[1:len(b)-1])) {
+		t.Errorf("summary contains the secret:\n%s", buf)
+	}
+	if !strings.Contains(buf.String(), `"a: login as *** failed"`) {
+		t.Errorf("summary does not contain the masked error:\n%s", buf)
+	}
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"WrapNGo/config"
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+)
+
+const (
+	selectorTagPrefix   = "tag:"
+	selectorRegexPrefix = "re:"
+	selectorNamePrefix  = "name:"
+	globChars           = "*?["
+)
+
+// The taskSelector type matches tasks by name, glob, regular expression or tag.
+//
+//	backup         exact name
+//	backup-*       glob (path.Match syntax)
+//	re:^backup-\d  regular expression
+//	tag:nightly    tag
+//	name:backup-*  exact name, even if it looks like one of the other selectors
+type taskSelector struct {
+	raw           string
+	tag           string
+	glob          string
+	name          string
+	reg           *regexp.Regexp
+	caseSensitive bool
+}
+
+// parseSelector parses the given selector.
+func parseSelector(raw string, caseSensitive bool) (s taskSelector, err error) {
+	s = taskSelector{raw: raw, caseSensitive: caseSensitive}
+	switch {
+	case strings.HasPrefix(raw, selectorNamePrefix):
+		s.name = s.fold(strings.TrimPrefix(raw, selectorNamePrefix))
+	case strings.HasPrefix(raw, selectorTagPrefix):
+		s.tag = strings.TrimPrefix(raw, selectorTagPrefix)
+		if s.tag == "" {
+			return s, fmt.Errorf("%w: empty tag in selector %q", errUsage, raw)
+		}
+	case strings.HasPrefix(raw, selectorRegexPrefix):
+		expr := strings.TrimPrefix(raw, selectorRegexPrefix)
+		if !caseSensitive {
+			expr = "(?i)" + expr
+		}
+		s.reg, err = regexp.Compile(expr)
+		if err != nil {
+			return s, fmt.Errorf("%w: invalid regular expression in selector %q: %v", errUsage, raw, err)
+		}
+	case strings.ContainsAny(raw, globChars):
+		s.glob = s.fold(raw)
+		_, err = path.Match(s.glob, "")
+		if err != nil {
+			return s, fmt.Errorf("%w: invalid glob in selector %q: %v", errUsage, raw, err)
+		}
+	default:
+		s.name = s.fold(raw)
+	}
+	return
+}
+
+// matches returns whether t is matched by the selector.
+func (s taskSelector) matches(t config.Task) bool {
+	switch {
+	case s.tag != "":
+		for _, tag := range t.Tags {
+			if s.fold(tag) == s.fold(s.tag) {
+				return true
+			}
+		}
+		return false
+	case s.reg != nil:
+		return s.reg.MatchString(t.Name)
+	case s.glob != "":
+		ok, _ := path.Match(s.glob, s.fold(t.Name))
+		return ok
+	}
+	return s.fold(t.Name) == s.name
+}
+
+// fold lower cases v if the selector is case-insensitive.
+func (s taskSelector) fold(v string) string {
+	if s.caseSensitive {
+		return v
+	}
+	return strings.ToLower(v)
+}
+
+// selectTasks returns every task matched by at least one of the selectors.
+// The tasks are ordered by the first selector matching them, each task is only returned once.
+// unmatched contains every selector that did not match any task.
+func selectTasks(tasks []config.Task, selectors []string, caseSensitive bool) (selected []config.Task, unmatched []string, err error) {
+	parsed := make([]taskSelector, len(selectors))
+	for i, raw := range selectors {
+		parsed[i], err = parseSelector(raw, caseSensitive)
+		if err != nil {
+			return
+		}
+	}
+
+	selected = make([]config.Task, 0)
+	seen := make(map[int]bool)
+	for _, s := range parsed {
+		found := false
+		for i, t := range tasks {
+			if !s.matches(t) {
+				continue
+			}
+			found = true
+			if seen[i] {
+				continue
+			}
+			seen[i] = true
+			selected = append(selected, t)
+		}
+		if !found {
+			unmatched = append(unmatched, s.raw)
+		}
+	}
+	return
+}
+
+// tagSelectors converts the given tags to selectors.
+func tagSelectors(tags []string) (selectors []string) {
+	for _, t := range tags {
+		selectors = append(selectors, selectorTagPrefix+t)
+	}
+	return
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"WrapNGo/config"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestSelectTasks(t *testing.T) {
+	tasks := []config.Task{
+		{Name: "backup-db", Tags: []string{"nightly", "DB"}},
+		{Name: "backup-media", Tags: []string{"nightly"}},
+		{Name: "Upload"},
+		{Name: "cleanup"},
+		{Name: "backup-*"},
+		{Name: "tag:nightly"},
+	}
+	tests := []struct {
+		name          string
+		selectors     []string
+		caseSensitive bool
+		want          []string
+		unmatched     []string
+	}{
+		{
+			name:      "exact name",
+			selectors: []string{"cleanup"},
+			want:      []string{"cleanup"},
+		},
+		{
+			name:      "case-insensitive name",
+			selectors: []string{"upload"},
+			want:      []string{"Upload"},
+		},
+		{
+			name:          "case-sensitive name",
+			selectors:     []string{"upload"},
+			caseSensitive: true,
+			unmatched:     []string{"upload"},
+		},
+		{
+			name:      "glob",
+			selectors: []string{"backup-*"},
+			want:      []string{"backup-db", "backup-media", "backup-*"},
+		},
+		{
+			name:      "glob with character class",
+			selectors: []string{"backup-[d]?"},
+			want:      []string{"backup-db"},
+		},
+		{
+			name:      "regular expression",
+			selectors: []string{`re:^(upload|clean)`},
+			want:      []string{"Upload", "cleanup"},
+		},
+		{
+			name:          "case-sensitive regular expression",
+			selectors:     []string{`re:^upload`},
+			caseSensitive: true,
+			unmatched:     []string{`re:^upload`},
+		},
+		{
+			name:      "tag",
+			selectors: []string{"tag:nightly"},
+			want:      []string{"backup-db", "backup-media"},
+		},
+		{
+			name:      "case-insensitive tag",
+			selectors: []string{"tag:db"},
+			want:      []string{"backup-db"},
+		},
+		{
+			name:      "name prefix matches the exact name",
+			selectors: []string{"name:backup-*", "name:tag:nightly"},
+			want:      []string{"backup-*", "tag:nightly"},
+		},
+		{
+			name:      "name prefix is case-insensitive",
+			selectors: []string{"name:UPLOAD"},
+			want:      []string{"Upload"},
+		},
+		{
+			name:      "ordered by the first matching selector without duplicates",
+			selectors: []string{"cleanup", "tag:nightly", "backup-db"},
+			want:      []string{"cleanup", "backup-db", "backup-media"},
+		},
+		{
+			name:      "unmatched selectors",
+			selectors: []string{"cleanup", "missing", "tag:weekly"},
+			want:      []string{"cleanup"},
+			unmatched: []string{"missing", "tag:weekly"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			selected, unmatched, err := selectTasks(tasks, tt.selectors, tt.caseSensitive)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			var names []string
+			for _, s := range selected {
+				names = append(names, s.Name)
+			}
+			if !reflect.DeepEqual(names, tt.want) {
+				t.Errorf("selected = %v, want %v", names, tt.want)
+			}
+			if !reflect.DeepEqual(unmatched, tt.unmatched) {
+				t.Errorf("unmatched = %v, want %v", unmatched, tt.unmatched)
+			}
+		})
+	}
+}
+
+func TestParseSelectorErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		selector string
+	}{
+		{name: "empty tag", selector: "tag:"},
+		{name: "invalid regular expression", selector: "re:("},
+		{name: "invalid glob", selector: "backup-[a"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseSelector(tt.selector, false)
+			if !errors.Is(err, errUsage) {
+				t.Errorf("error = %v, want %v", err, errUsage)
+			}
+		})
+	}
+}
@@ -4,13 +4,16 @@ import (
 	"WrapNGo/config"
 	"WrapNGo/logger"
 	"WrapNGo/parsing"
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
 	"os/signal"
 	"reflect"
 	"regexp"
+	"strconv"
 	"strings"
+	"sync"
 	"syscall"
 	"time"
 )
@@ -23,7 +26,8 @@ const (
 var (
 	wildcardReg      = regexp.QuoteMeta("(") + "(.*)" + regexp.QuoteMeta(")")
 	dateReg          = regexp.MustCompile(fmt.Sprintf("(?i)(%sDate%s)", config.PlaceholderChar, config.PlaceholderChar))
-	mapReg           = regexp.MustCompile("\"(.+?)\":\"(.+?)\"[,}]")
+	profileReg       = regexp.MustCompile(fmt.Sprintf("(?i)%sProfile%s", config.PlaceholderChar, config.PlaceholderChar))
+	mapReg           = regexp.MustCompile("\"([^\"]+)\":\"(.+?)\"[,}]")
 	objectReg        = regexp.MustCompile("[{\"\\s](.+?)\"?:\"(.*?)\"[,}]")
 	dynamicReg       = regexp.MustCompile("%Dynamic\\.(.*?)%")
 	globalDynamicReg = regexp.MustCompile("%GlobalDynamic\\.(.*?)%")
@@ -34,38 +38,87 @@ var (
 	envFuncReg = regexp.MustCompile(
 		fmt.Sprintf("(?i)(%sEnv%s%s)", config.PlaceholderChar, wildcardReg, config.PlaceholderChar),
 	)
+
+	// literalReg matches the placeholders of the extra arguments and the secrets (see expandLiterals).
+	literalReg = regexp.MustCompile(argsReg.String() + "|" + secretFuncReg.String())
 )
 
 // RunTask will execute the given Task.
 // It will start the Pre- and Post-Operations as well as the job.
-func RunTask(t config.Task, globalDynamic map[string]any) (err error) {
+// Every value of the config is read from conf, a reload while the task is running does not affect it.
+// Failing steps without StopIfUnsuccessful are logged as warnings and do not fail the task.
+func RunTask(t config.Task, conf *config.Snapshot) (err error) {
 	usrItr := make(chan os.Signal, 1)
 	opItr := make(chan error, 1)
 	signal.Notify(usrItr, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(usrItr)
+
+	err = resolveSecrets(t, conf)
+	if err != nil {
+		return
+	}
 
 	// Compress source if enabled.
-	t.Compression.InMemoryCompressionLimit = replacePlaceholders(t, globalDynamic, t.Compression.InMemoryCompressionLimit)[0]
-	t.Compression.PathToCompress = replacePlaceholders(t, globalDynamic, t.Compression.PathToCompress)[0]
+	t.Compression.InMemoryCompressionLimit, err = resolveValue(t, conf, t.Compression.InMemoryCompressionLimit)
+	if err != nil {
+		return
+	}
+	t.Compression.PathToCompress, err = resolveValue(t, conf, t.Compression.PathToCompress)
+	if err != nil {
+		return
+	}
 	if t.Compression.PathToCompress != "" {
 		var path string
 		path, err = compress(t.Compression)
 
 		// Only write back if compressing was successful.
-		if err != nil && t.StopIfUnsuccessful {
-			return
+		if err != nil {
+			err = fmt.Errorf("%s: %w: %v", t.Name, ErrCompressionFailed, err)
+			if t.StopIfUnsuccessful {
+				return
+			}
+			logger.Warnf("%v, continuing with the remaining steps\n", err)
+			err = nil
+		} else {
+			t.Compression.PathToCompress = path
 		}
-		t.Compression.PathToCompress = path
 	}
 
 	// Execute PreOperations if available.
+	// opFailure is the first error of the parallel PreOperations which should stop the task,
+	// it is only read after all of them have finished.
+	var (
+		opWg      sync.WaitGroup
+		opMux     sync.Mutex
+		opFailure error
+	)
 	if t.AllowParallelOperationsRun {
 		for i, preOp := range t.PreOperations {
 			if !preOp.Enabled {
 				continue
 			}
 
+			// Only the first failing operation which should stop the task is forwarded to the job.
+			opWg.Add(1)
 			go func(o config.Operation, num int) {
-				opItr <- runOperation(o, t, globalDynamic, usrItr, jobPreOperation, num)
+				defer opWg.Done()
+				opErr := runOperation(o, t, conf, usrItr, jobPreOperation, num)
+				if opErr == nil {
+					return
+				}
+				if !o.StopIfUnsuccessful {
+					logger.Warnf("%v, continuing with the remaining steps\n", opErr)
+					return
+				}
+
+				opMux.Lock()
+				defer opMux.Unlock()
+				if opFailure != nil {
+					logger.Error(opErr)
+					return
+				}
+				opFailure = opErr
+				opItr <- opErr
 			}(preOp, i+1)
 		}
 	} else {
@@ -74,22 +127,33 @@ func RunTask(t config.Task, globalDynamic map[string]any) (err error) {
 				continue
 			}
 
-			err = runOperation(preOp, t, globalDynamic, usrItr, jobPreOperation, i+1)
+			err = runOperation(preOp, t, conf, usrItr, jobPreOperation, i+1)
 			if err != nil {
-				if preOp.StopIfUnsuccessful {
+				if preOp.StopIfUnsuccessful || errors.Is(err, ErrUserInterrupt) {
 					return
 				}
+				logger.Warnf("%v, continuing with the remaining steps\n", err)
+				err = nil
 			}
 		}
 	}
 
 	// Run the defined job.
-	err = runJob(t, globalDynamic, usrItr, opItr)
+	err = runJob(t, conf, usrItr, opItr)
+	if errors.Is(err, ErrUserInterrupt) {
+		return
+	}
+
+	// The parallel PreOperations may still be running, their failure stops the task even if the job has succeeded.
+	opWg.Wait()
+	if opFailure != nil {
+		return opFailure
+	}
 	if err != nil {
 		if t.StopIfUnsuccessful {
 			return
 		}
-		logger.Error(err)
+		logger.Warnf("%v, continuing with the remaining steps\n", err)
 		err = nil
 	}
 
@@ -99,40 +163,40 @@ func RunTask(t config.Task, globalDynamic map[string]any) (err error) {
 			continue
 		}
 
-		err = runOperation(postOp, t, globalDynamic, usrItr, jobPostOperation, i+1)
-		if err != nil && postOp.StopIfUnsuccessful {
-			return
+		err = runOperation(postOp, t, conf, usrItr, jobPostOperation, i+1)
+		if err != nil {
+			if postOp.StopIfUnsuccessful || errors.Is(err, ErrUserInterrupt) {
+				return
+			}
+			logger.Warnf("%v, continuing with the remaining steps\n", err)
+			err = nil
 		}
 	}
 	return
 }
 
 // runJob executes the actual binary action.
-func runJob(t config.Task, globalDynamic map[string]any, itrChan chan os.Signal, opItr chan error) (err error) {
+func runJob(t config.Task, conf *config.Snapshot, itrChan chan os.Signal, opItr chan error) (err error) {
 	job := make(chan error)
-	cmd := config.Current().GeneralSettings.GlobalCommand
-	if t.Command != "" {
-		cmd = t.Command
+	cmd, args, err := buildCommand(t, conf, jobCommand(t, conf), t.Arguments)
+	if err != nil {
+		return
 	}
-	cmd = replacePlaceholders(t, globalDynamic, cmd)[0]
-
-	// Since flags can contain spaces, separate them
-	// and append them to the args slice.
-	args := make([]string, 0)
-	for _, f := range t.Arguments {
-		flags := strings.Split(f, " ")
-		args = append(args, flags...)
+	t.RemovePathAfterJobCompletes, err = resolveValue(t, conf, t.RemovePathAfterJobCompletes)
+	if err != nil {
+		return
+	}
+	c := exec.Command(cmd, args...)
+	c.Env, err = taskEnv(t, conf)
+	if err != nil {
+		return
 	}
-
-	args = replacePlaceholders(t, globalDynamic, args...)
-	replacedArgs := strings.Join(replacePlaceholders(t, globalDynamic, args...), " ")
-	c := exec.Command(cmd, escapeSplit(replacedArgs, "\\", " ")...)
 	c.Stdout = logger.JobWriter()
 	c.Stdin = os.Stdin
-	c.Stderr = os.Stderr
+	c.Stderr = logger.MaskWriter(os.Stderr)
 	err = c.Start()
 	if err != nil {
-		logger.Errorf("%s: %v", t.Name, err)
+		return fmt.Errorf("%s: %w: %v", t.Name, ErrJobFailed, err)
 	}
 
 	go func() {
@@ -163,27 +227,26 @@ func runJob(t config.Task, globalDynamic map[string]any, itrChan chan os.Signal,
 		return
 	}
 
-	t.RemovePathAfterJobCompletes = replacePlaceholders(t, globalDynamic, t.RemovePathAfterJobCompletes)[0]
 	select {
 	case <-itrChan:
 		err = c.Process.Kill()
 		if err != nil {
 			logger.Error(err)
 		}
-		return fmt.Errorf("%s: %v", t.Name, ErrUserInterrupt)
-	case <-opItr:
+		return fmt.Errorf("%s: %w", t.Name, ErrUserInterrupt)
+	case opErr := <-opItr:
 		err = c.Process.Kill()
 		if err != nil {
 			logger.Error(err)
 		}
 		removePath(t.RemovePathAfterJobCompletes)
-		return fmt.Errorf("%s: %v: %v", t.Name, jobPreOperation, ErrOperationFailed)
+		return opErr
 	case err = <-job:
 	}
 
 	removePath(t.RemovePathAfterJobCompletes)
 	if err != nil {
-		return fmt.Errorf("%s: %v: %s", t.Name, ErrJobFailed, err)
+		return fmt.Errorf("%s: %w: %s", t.Name, ErrJobFailed, err)
 	}
 
 	logger.Infof("Job \"%s\" completed successfully", t.Name)
@@ -191,24 +254,17 @@ func runJob(t config.Task, globalDynamic map[string]any, itrChan chan os.Signal,
 }
 
 // runOperation runs the given operation and blocks until it has finished.
-func runOperation(o config.Operation, t config.Task, globalDynamic map[string]any, itrChan chan os.Signal, oType string, oNum int) (err error) {
+func runOperation(o config.Operation, t config.Task, conf *config.Snapshot, itrChan chan os.Signal, oType string, oNum int) (err error) {
 	logger.Infof("%s: Executing %s #%d\n", t.Name, oType, oNum)
-	cmd := config.Current().GeneralSettings.GlobalCommand
-	if t.Command != "" {
-		cmd = o.Command
+	cmd, args, err := buildCommand(t, conf, operationCommand(t, conf, o), o.Arguments)
+	if err != nil {
+		return
 	}
-	cmd = replacePlaceholders(t, globalDynamic, cmd)[0]
-
-	// Since flags can contain spaces, separate them
-	// and append them to the args slice.
-	args := make([]string, 0)
-	for _, f := range o.Arguments {
-		flags := strings.Split(f, " ")
-		args = append(args, flags...)
+	c := exec.Command(cmd, args...)
+	c.Env, err = taskEnv(t, conf)
+	if err != nil {
+		return
 	}
-	args = replacePlaceholders(t, globalDynamic, args...)
-	replacedArgs := strings.Join(replacePlaceholders(t, globalDynamic, args...), " ")
-	c := exec.Command(cmd, escapeSplit(replacedArgs, "\\", " ")...)
 	c.Stdin = os.Stdin
 	if o.CaptureStdOut {
 		c.Stdout = logger.OperationWriter()
@@ -217,7 +273,7 @@ func runOperation(o config.Operation, t config.Task, globalDynamic map[string]an
 	done := make(chan error, 1)
 	err = c.Start()
 	if err != nil {
-		return
+		return fmt.Errorf("%s: %s #%d: %w: %v", t.Name, oType, oNum, ErrOperationFailed, err)
 	}
 
 	go func() {
@@ -233,27 +289,133 @@ func runOperation(o config.Operation, t config.Task, globalDynamic map[string]an
 	// If timeout reached, stop the command execution.
 	case <-timeout:
 		err = c.Process.Kill()
-		return fmt.Errorf("%s: %s - %v", t.Name, oType, ErrTimeout)
+		return fmt.Errorf("%s: %s #%d: %w", t.Name, oType, oNum, ErrTimeout)
 
 	// User has interrupted, stop command execution.
 	case <-itrChan:
 		err = c.Process.Kill()
-		return fmt.Errorf("%s: %s - %v", t.Name, oType, ErrUserInterrupt)
+		return fmt.Errorf("%s: %s #%d: %w", t.Name, oType, oNum, ErrUserInterrupt)
 
 	// Command finished.
 	case err = <-done:
 	}
 
 	if err != nil {
-		return fmt.Errorf("%s: %s: executed operation caught an error: %v", t.Name, oType, err)
+		return fmt.Errorf("%s: %s #%d: %w: %v", t.Name, oType, oNum, ErrOperationFailed, err)
 	}
 	return
 }
 
+// buildCommand resolves the command and arguments of a job or operation.
+// The command is chosen by jobCommand or operationCommand.
+func buildCommand(t config.Task, conf *config.Snapshot, command string, arguments []string) (cmd string, args []string, err error) {
+	cmd, err = resolveValue(t, conf, command)
+	if err != nil {
+		return
+	}
+
+	// Since flags can contain spaces, separate them
+	// and append them to the args slice.
+	args = make([]string, 0)
+	for _, f := range arguments {
+		flags := strings.Split(f, " ")
+		args = append(args, flags...)
+	}
+	args = replacePlaceholders(t, conf, args...)
+	replacedArgs := strings.Join(replacePlaceholders(t, conf, args...), " ")
+
+	// The extra arguments and secrets are inserted after splitting to pass them as they are.
+	args = make([]string, 0)
+	for _, a := range escapeSplit(replacedArgs, "\\", " ") {
+		var parts []string
+		parts, err = expandLiterals(a, t.Args)
+		if err != nil {
+			return cmd, args, fmt.Errorf("%s: %w", t.Name, err)
+		}
+		for _, p := range parts {
+			if p != "" {
+				args = append(args, p)
+			}
+		}
+	}
+	return
+}
+
+// resolveValue replaces every placeholder of the single value v, the extra arguments are separated by spaces.
+func resolveValue(t config.Task, conf *config.Snapshot, v string) (resolved string, err error) {
+	parts, err := expandLiterals(replacePlaceholders(t, conf, v)[0], t.Args)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", t.Name, err)
+	}
+	return strings.Join(parts, " "), nil
+}
+
+// expandLiterals replaces the %Args%, %Args.N% and %Secret(name)% placeholders of v.
+// They are replaced after every other placeholder and their values are inserted as they are,
+// they are neither searched for further placeholders nor split at spaces.
+// %Args% inserts all extra arguments, each of them ends the current part and starts a new one,
+// e.g. "-f=%Args%" with the extra arguments "a b" and "c" results in []string{"-f=a b", "c"}.
+// %Args.N% only inserts the N-th one (starting at 1).
+func expandLiterals(v string, extra []string) (parts []string, err error) {
+	part := ""
+	last := 0
+	for _, m := range literalReg.FindAllStringSubmatchIndex(v, -1) {
+		part += v[last:m[0]]
+		last = m[1]
+		switch {
+		case m[4] >= 0:
+			var value string
+			value, err = secretValue(v[m[4]:m[5]])
+			if err != nil {
+				return nil, fmt.Errorf("%w: %v", ErrInvalidConfig, err)
+			}
+			part += value
+		case m[2] >= 0:
+			n, aErr := strconv.Atoi(v[m[2]:m[3]])
+			if aErr == nil && n > 0 && n <= len(extra) {
+				part += extra[n-1]
+			}
+		default:
+			for i, e := range extra {
+				if i > 0 {
+					parts = append(parts, part)
+					part = ""
+				}
+				part += e
+			}
+		}
+	}
+	return append(parts, part+v[last:]), nil
+}
+
+// jobCommand returns the command of the job of t, the GlobalCommand if t has no Command.
+func jobCommand(t config.Task, conf *config.Snapshot) string {
+	if t.Command == "" {
+		return conf.GeneralSettings().GlobalCommand
+	}
+	return t.Command
+}
+
+// operationCommand returns the command of the operation o of t.
+// Operations of a task without Command run the GlobalCommand and ignore their own Command,
+// otherwise the Command of the operation is used even if it is empty.
+func operationCommand(t config.Task, conf *config.Snapshot, o config.Operation) string {
+	if t.Command == "" {
+		return conf.GeneralSettings().GlobalCommand
+	}
+	return o.Command
+}
+
 // replacePlaceholders checks the given strings for placeholders and replaces them accordingly.
-func replacePlaceholders(t config.Task, globalDynamic map[string]any, values ...string) (replaced []string) {
+func replacePlaceholders(t config.Task, conf *config.Snapshot, values ...string) (replaced []string) {
 	tm := time.Now()
-	dateFormat := config.Current().GeneralSettings.DateFormat
+	dateFormat := t.DateFormat
+	if dateFormat == "" {
+		dateFormat = conf.GeneralSettings().DateFormat
+	}
+	// mapReg only matches string values, numbers and booleans are replaced by their text.
+	globalDynamic := scalarsAsStrings(conf.GlobalDynamic())
+	t.Dynamic = scalarsAsStrings(t.Dynamic)
 	fElem := reflect.ValueOf(&t).Elem()
 	if len(values) < 1 {
 		return
@@ -321,9 +483,16 @@ func replacePlaceholders(t config.Task, globalDynamic map[string]any, values ...
 			v = strings.ReplaceAll(v, found[1], env)
 		}
 
+		// Check for the profile placeholder.
+		v = profileReg.ReplaceAllLiteralString(v, conf.Profile())
+
 		// Task dependent placeholders.
 		for i := 0; i < fElem.NumField(); i++ {
 			fName := fElem.Type().Field(i).Name
+			if fName == "Args" {
+				// Replaced by expandLiterals.
+				continue
+			}
 
 			fieldReg, err = regexp.Compile(fmt.Sprintf("(?i)(%s%s%s)", config.PlaceholderChar, fName, config.PlaceholderChar))
 			if err != nil {
@@ -353,6 +522,24 @@ func replacePlaceholders(t config.Task, globalDynamic map[string]any, values ...
 	return
 }
 
+// scalarsAsStrings returns a copy of m whose numbers and booleans are converted into strings.
+// Maps, lists and null values are kept, they are never replaced.
+func scalarsAsStrings(m map[string]any) map[string]any {
+	if m == nil {
+		return nil
+	}
+	converted := make(map[string]any, len(m))
+	for k, v := range m {
+		switch v.(type) {
+		case nil, string, map[string]any, []any, []map[string]any, []string:
+			converted[k] = v
+		default:
+			converted[k] = fmt.Sprint(v)
+		}
+	}
+	return converted
+}
+
 // escapeSplit will check the value for an escaped sequence before splitting to omit wrong splits.
 // escapeSeq is the string that should prevent the split.
 // separator is the string that is used to split.
@@ -0,0 +1,106 @@
+package main
+
+import "testing"
+
+func TestCommands(t *testing.T) {
+	conf := reloadFiles(t, map[string]string{
+		"config.yaml": `Version: 1
+GeneralSettings:
+  GlobalCommand: global
+Tasks:
+  - Name: own
+    Command: job
+    PreOperations:
+      - Command: op
+      - Arguments: [a]
+  - Name: none
+    PreOperations:
+      - Arguments: [a]
+      - Command: op
+`,
+		"defaults.yaml": `Version: 1
+Defaults:
+  Command: defaults
+Tasks:
+  - Name: defaults
+    PreOperations:
+      - Arguments: [a]
+`,
+	})
+
+	tests := []struct {
+		name string
+		task string
+		op   int
+		want string
+	}{
+		{name: "job", task: "own", op: -1, want: "job"},
+		{name: "own operation command", task: "own", op: 0, want: "op"},
+		{name: "operation without command does not fall back", task: "own", op: 1, want: ""},
+		{name: "job without command", task: "none", op: -1, want: "global"},
+		{name: "task without command", task: "none", op: 0, want: "global"},
+		{name: "task without command ignores the operation command", task: "none", op: 1, want: "global"},
+		{name: "defaults", task: "defaults", op: -1, want: "defaults"},
+		{name: "operation uses the defaults", task: "defaults", op: 0, want: "defaults"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			task, ok := conf.Task(tt.task)
+			if !ok {
+				t.Fatalf("task %q not found", tt.task)
+			}
+			cmd := jobCommand(task, conf)
+			if tt.op >= 0 {
+				cmd = operationCommand(task, conf, task.PreOperations[tt.op])
+			}
+			if cmd != tt.want {
+				t.Errorf("command = %q, want %q", cmd, tt.want)
+			}
+		})
+	}
+}
+
+func TestDynamicScalars(t *testing.T) {
+	conf := reloadFiles(t, map[string]string{
+		"config.yaml": `Version: 1
+GeneralSettings:
+  GlobalCommand: echo
+GlobalDynamic:
+  Port: 5432
+  Ratio: 0.5
+Tasks:
+  - Name: a
+    Dynamic:
+      Keep: 3
+      Verbose: true
+      Host: db
+      List: [a, b]
+`,
+	})
+	task, ok := conf.Task("a")
+	if !ok {
+		t.Fatal("task a not found")
+	}
+
+	tests := []struct {
+		arg  string
+		want string
+	}{
+		{arg: "%Dynamic.Keep%", want: "3"},
+		{arg: "%Dynamic.Verbose%", want: "true"},
+		{arg: "%Dynamic.Host%:%GlobalDynamic.Port%", want: "db:5432"},
+		{arg: "%GlobalDynamic.Ratio%", want: "0.5"},
+		{arg: "%Dynamic.List%", want: "%Dynamic.List%"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.arg, func(t *testing.T) {
+			_, args, err := buildCommand(task, conf, "echo", []string{tt.arg})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(args) != 1 || args[0] != tt.want {
+				t.Errorf("arguments = %q, want [%q]", args, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,338 @@
+package main
+
+import (
+	"WrapNGo/config"
+	"WrapNGo/logger"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/AlecAivazis/survey/v2/terminal"
+)
+
+// newFileOption is the option of the file selection creating a new config file.
+const newFileOption = "New file"
+
+// createTask asks for the values of a new task, shows a preview and appends the task to the chosen config file.
+// The configs are reloaded afterwards, the new task can be executed right away.
+func createTask(conf *config.Snapshot) (err error) {
+	t, err := askTask(conf)
+	if err != nil {
+		return
+	}
+	path, exists, err := askTaskFile()
+	if err != nil {
+		return
+	}
+
+	preview, err := config.TaskPreview(path, t)
+	if err != nil {
+		return
+	}
+	fmt.Printf("\n%s\n", strings.TrimRight(string(preview), "\n"))
+	msg := fmt.Sprintf("Create %s containing the task?", path)
+	if exists {
+		msg = fmt.Sprintf("Append the task to %s? Comments inside the file are not kept", path)
+	}
+	confirm := false
+	err = ask(&survey.Confirm{Message: msg, Default: true}, &confirm)
+	if err != nil || !confirm {
+		return
+	}
+
+	created, err := config.AppendTask(path, t)
+	if err != nil {
+		return
+	}
+	if created {
+		logger.Infof("Created %s containing task %q\n", path, t.Name)
+	} else {
+		logger.Infof("Task %q appended to %s\n", t.Name, path)
+	}
+
+	conf, err = config.Reload()
+	if err != nil {
+		return
+	}
+	_, ok := conf.Task(t.Name)
+	if !ok {
+		logger.Warnf("%s is not loaded, add it to the Include list of the main config or move it into the config directory\n", path)
+	}
+	return
+}
+
+// askTask asks for the values of a new task, the name must not be used by a task of conf yet.
+func askTask(conf *config.Snapshot) (t config.Task, err error) {
+	err = ask(&survey.Input{Message: "Name"}, &t.Name, survey.WithValidator(survey.Required), survey.WithValidator(func(ans any) error {
+		_, ok := conf.Task(strings.TrimSpace(ans.(string)))
+		if ok {
+			return fmt.Errorf("a task named %q already exists", strings.TrimSpace(ans.(string)))
+		}
+		return nil
+	}))
+	if err != nil {
+		return
+	}
+	t.Name = strings.TrimSpace(t.Name)
+
+	command := &survey.Input{Message: "Command"}
+	opts := []survey.AskOpt{survey.WithValidator(survey.Required)}
+	global := conf.GeneralSettings().GlobalCommand
+	if global != "" {
+		command.Help = fmt.Sprintf("Leave empty to use GeneralSettings.GlobalCommand (%s)", global)
+		opts = nil
+	}
+	err = ask(command, &t.Command, opts...)
+	if err != nil {
+		return
+	}
+	t.Arguments, err = askLines("Arguments (one per line)")
+	if err != nil {
+		return
+	}
+
+	tags := ""
+	err = ask(&survey.Input{Message: "Tags (comma separated)"}, &tags)
+	if err != nil {
+		return
+	}
+	t.Tags = splitList(tags)
+
+	deps := ""
+	err = ask(&survey.Input{Message: "Depends on (comma separated task names)"}, &deps, survey.WithValidator(func(ans any) error {
+		for _, name := range splitList(ans.(string)) {
+			_, ok := conf.Task(name)
+			if !ok {
+				return fmt.Errorf("task %q does not exist", name)
+			}
+		}
+		return nil
+	}))
+	if err != nil {
+		return
+	}
+	t.DependsOn = splitList(deps)
+
+	t.Dynamic, err = askDynamic()
+	if err != nil {
+		return
+	}
+	t.Compression, err = askCompression()
+	if err != nil {
+		return
+	}
+	t.PreOperations, err = askOperations("pre-operation", t.Command, global)
+	if err != nil {
+		return
+	}
+	if len(t.PreOperations) > 0 {
+		err = ask(&survey.Confirm{Message: "Run the pre-operations in parallel to the job?"}, &t.AllowParallelOperationsRun)
+		if err != nil {
+			return
+		}
+	}
+	t.PostOperations, err = askOperations("post-operation", t.Command, global)
+	return
+}
+
+// askDynamic asks for the Dynamic values of a task as "Key=Value" lines.
+func askDynamic() (dynamic map[string]any, err error) {
+	lines, err := askLines("Dynamic values (one Key=Value per line, used via %Dynamic.Key%)", survey.WithValidator(func(ans any) error {
+		for _, line := range splitLines(ans.(string)) {
+			key, _, ok := strings.Cut(line, "=")
+			if !ok || strings.TrimSpace(key) == "" {
+				return fmt.Errorf("%q is not in the format Key=Value", line)
+			}
+		}
+		return nil
+	}))
+	if err != nil || len(lines) == 0 {
+		return
+	}
+
+	dynamic = make(map[string]any, len(lines))
+	for _, line := range lines {
+		key, value, _ := strings.Cut(line, "=")
+		dynamic[strings.TrimSpace(key)] = value
+	}
+	return
+}
+
+// askCompression asks whether a path should be compressed before the job starts and how.
+func askCompression() (c config.CompressionOptions, err error) {
+	compress := false
+	err = ask(&survey.Confirm{Message: "Compress a path before the job starts?"}, &compress)
+	if err != nil || !compress {
+		return
+	}
+
+	err = ask(&survey.Input{Message: "Path to compress"}, &c.PathToCompress, survey.WithValidator(survey.Required))
+	if err != nil {
+		return
+	}
+	err = ask(&survey.Input{
+		Message: "Path of the archive file (empty for <name>-<date>.tar.gz next to the path)",
+		Help:    "The archive is written to exactly this file, e.g. /backups/photos.tar.gz. A directory can not be used",
+	}, &c.OutputPath)
+	if err != nil {
+		return
+	}
+	err = ask(&survey.Input{Message: "In-memory compression limit", Default: "1GB"}, &c.InMemoryCompressionLimit, survey.WithValidator(func(ans any) error {
+		if !config.IsCompressionLimit(ans.(string)) {
+			return errors.New("expected a whole number followed by B, KB, MB or GB")
+		}
+		return nil
+	}))
+	if err != nil {
+		return
+	}
+	err = ask(&survey.Confirm{Message: "Overwrite an existing archive?"}, &c.OverwriteCompressed)
+	if err != nil {
+		return
+	}
+	err = ask(&survey.Confirm{Message: "Keep the path structure inside the archive?"}, &c.RetainStructure)
+	return
+}
+
+// askOperations asks for any number of operations of the given kind, e.g. "pre-operation".
+// Operations of a task without Command run global, the GlobalCommand, otherwise their command is required.
+func askOperations(kind, taskCommand, global string) (ops []config.Operation, err error) {
+	for {
+		add := false
+		err = ask(&survey.Confirm{Message: fmt.Sprintf("Add a %s?", kind)}, &add)
+		if err != nil || !add {
+			return
+		}
+
+		o := config.Operation{Enabled: true}
+		if taskCommand == "" {
+			logger.Infof("The task has no command, the %s runs GeneralSettings.GlobalCommand (%s)\n", kind, global)
+		} else {
+			err = ask(&survey.Input{Message: fmt.Sprintf("Command of %s #%d", kind, len(ops)+1)}, &o.Command, survey.WithValidator(survey.Required))
+			if err != nil {
+				return
+			}
+		}
+		o.Arguments, err = askLines("Arguments (one per line)")
+		if err != nil {
+			return
+		}
+
+		timeout := ""
+		err = ask(&survey.Input{Message: "Seconds until timeout (0 to wait until it has finished)", Default: "0"}, &timeout, survey.WithValidator(func(ans any) error {
+			n, err := strconv.Atoi(strings.TrimSpace(ans.(string)))
+			if err != nil || n < 0 {
+				return errors.New("expected a whole number of seconds")
+			}
+			return nil
+		}))
+		if err != nil {
+			return
+		}
+		o.SecondsUntilTimeout, _ = strconv.Atoi(strings.TrimSpace(timeout))
+
+		err = ask(&survey.Confirm{Message: "Fail the task if the operation fails?", Default: true}, &o.StopIfUnsuccessful)
+		if err != nil {
+			return
+		}
+		err = ask(&survey.Confirm{Message: "Log the output of the operation?", Default: true}, &o.CaptureStdOut)
+		if err != nil {
+			return
+		}
+		ops = append(ops, o)
+	}
+}
+
+// askTaskFile asks for the config file the task is written to, either a loaded one or a new one.
+// A relative path of a new file is resolved against the config directory.
+func askTaskFile() (path string, exists bool, err error) {
+	paths, err := config.Files()
+	if err != nil && !errors.Is(err, config.ErrNotFound) {
+		return
+	}
+
+	selected := ""
+	err = ask(&survey.Select{Message: "Config file to add the task to", Options: append(paths, newFileOption)}, &selected)
+	if err != nil {
+		return
+	}
+	if selected != newFileOption {
+		return selected, true, nil
+	}
+
+	dir, err := config.Dir()
+	if err != nil {
+		return
+	}
+	err = ask(&survey.Input{
+		Message: "Path of the new file",
+		Help:    fmt.Sprintf("The format is chosen by the extension (%s), relative paths are resolved against %s", strings.Join(config.FormatNames(), ", "), dir),
+		Default: "tasks.yaml",
+	}, &path, survey.WithValidator(func(ans any) error {
+		p := resolvePath(dir, ans.(string))
+		_, err := config.FormatOf(p)
+		if err != nil {
+			return err
+		}
+		_, err = os.Stat(p)
+		if err == nil {
+			return fmt.Errorf("%s already exists, select it from the list instead", p)
+		}
+		return nil
+	}))
+	if err != nil {
+		return
+	}
+	return resolvePath(dir, path), false, nil
+}
+
+// askLines asks for multiple lines and returns the ones which are not empty.
+func askLines(message string, opts ...survey.AskOpt) (lines []string, err error) {
+	text := ""
+	err = ask(&survey.Multiline{Message: message}, &text, opts...)
+	return splitLines(text), err
+}
+
+// ask asks a single question, an interrupted prompt is returned as ErrUserInterrupt.
+func ask(p survey.Prompt, response any, opts ...survey.AskOpt) error {
+	err := survey.AskOne(p, response, opts...)
+	if errors.Is(err, terminal.InterruptErr) {
+		return ErrUserInterrupt
+	}
+	return err
+}
+
+// splitLines returns the lines of text which are not empty, without their surrounding whitespace.
+func splitLines(text string) (lines []string) {
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return
+}
+
+// splitList returns the comma separated values of v which are not empty, without their surrounding whitespace.
+func splitList(v string) (values []string) {
+	for _, value := range strings.Split(v, ",") {
+		value = strings.TrimSpace(value)
+		if value != "" {
+			values = append(values, value)
+		}
+	}
+	return
+}
+
+// resolvePath returns path resolved against dir if it is relative.
+func resolvePath(dir, path string) string {
+	path = strings.TrimSpace(path)
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(dir, path)
+}